@@ -0,0 +1,154 @@
+// app.go
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/config"
+	"telegrarmchatbot/internal/service"
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+)
+
+// App owns everything the handlers need. Each App is independent, so several
+// bots can run in one process and handlers can be exercised against an in-memory store.
+type App struct {
+	config   *config.Config
+	store    db.Store
+	sessions state.SessionManager
+	holds    state.Holds
+	service  *service.BookingService
+	now      func() time.Time
+	logger   *log.Logger
+}
+
+// NewApp wires the session, hold and booking services around store.
+// The postgres session backend requires store to be a *db.Postgres.
+func NewApp(cfg *config.Config, store db.Store, logger *log.Logger, now func() time.Time) (*App, error) {
+	var holds state.Holds
+	var sessions state.SessionManager
+	switch cfg.Sessions.Backend {
+	case "postgres":
+		pg, ok := store.(*db.Postgres)
+		if !ok {
+			return nil, fmt.Errorf("postgres sessions need a postgres store, got %T", store)
+		}
+		// Holds are shared like the sessions, so replicas agree on what is free
+		holds = db.NewPostgresHolds(pg.DB, pg.Timeout, now)
+		sessions = db.NewPostgresSessionManager(pg.DB, pg.Timeout, holds)
+	default:
+		holds = state.NewHoldManager(now)
+		sessions = state.NewMemorySessionManager(holds)
+	}
+
+	return &App{
+		config:   cfg,
+		store:    store,
+		sessions: sessions,
+		holds:    holds,
+		service:  service.NewBookingService(store, store, holds, cfg.Booking, now),
+		now:      now,
+		logger:   logger,
+	}, nil
+}
+
+// registerHandlers attaches the command and callback handlers to b
+func (a *App) registerHandlers(b *bot.Bot) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, a.startHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/help", bot.MatchTypeExact, a.helpHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/book", bot.MatchTypeExact, a.bookHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/reschedule", bot.MatchTypeExact, a.rescheduleHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/cancel", bot.MatchTypeExact, a.cancelHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "", bot.MatchTypePrefix, a.callbackHandler)
+}
+
+// loadSession returns the user's session, treating one idle past the TTL as
+// gone so it can't capture later messages before the sweeper removes it.
+func (a *App) loadSession(ctx context.Context, userID int64) (*state.BookingSession, error) {
+	session, err := a.sessions.GetSession(ctx, userID)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	if session.Expired(a.now(), a.config.Sessions.TTLDuration()) {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// saveSession stores the session, telling the user if that failed.
+// It returns false when the handler should stop.
+func (a *App) saveSession(ctx context.Context, b *bot.Bot, chatID int64, userID int64, session *state.BookingSession) bool {
+	session.LastActivity = a.now()
+	if session.ChatID == 0 {
+		session.ChatID = chatID
+	}
+	if err := a.sessions.SetSession(ctx, userID, session); err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, something went wrong. Please try again later.",
+		})
+		a.logger.Printf("Error saving session: %v", err)
+		return false
+	}
+
+	// Keep the user's hold for as long as they keep answering, not just
+	// hold_duration from when they picked the range
+	if err := a.holds.Extend(ctx, userID, a.config.Booking.HoldTTL()); err != nil {
+		a.logger.Printf("Error extending hold: %v", err)
+	}
+	return true
+}
+
+// clearSession ends the user's booking conversation
+func (a *App) clearSession(ctx context.Context, userID int64) {
+	if err := a.sessions.ClearSession(ctx, userID); err != nil {
+		a.logger.Printf("Error clearing session: %v", err)
+	}
+}
+
+// sweepSessions removes idle booking conversations every minute until ctx is
+// done, releasing their holds and telling each user what happened.
+func (a *App) sweepSessions(ctx context.Context, b *bot.Bot) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cutoff := a.now().Add(-a.config.Sessions.TTLDuration())
+		expired, err := a.sessions.RemoveExpired(ctx, cutoff)
+		if err != nil {
+			a.logger.Printf("Error removing expired sessions: %v", err)
+			continue
+		}
+
+		for _, session := range expired {
+			if err := a.holds.Release(ctx, session.UserID); err != nil {
+				a.logger.Printf("Error releasing hold: %v", err)
+			}
+
+			chatID := session.ChatID
+			if chatID == 0 {
+				chatID = session.UserID
+			}
+			text := "⌛ Your booking was cancelled due to inactivity. Type /book to start again."
+			if session.BookingID != 0 {
+				text = "⌛ Rescheduling timed out due to inactivity. Your booking has not been changed."
+			}
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   text,
+			})
+		}
+	}
+}
@@ -0,0 +1,337 @@
+// app_test.go
+
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/config"
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// fakeTelegram answers every Bot API call and keeps the texts the bot sent
+type fakeTelegram struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err == nil {
+		if text := r.FormValue("text"); text != "" {
+			f.mu.Lock()
+			f.texts = append(f.texts, text)
+			f.mu.Unlock()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if path.Base(r.URL.Path) == "answerCallbackQuery" {
+		io.WriteString(w, `{"ok":true,"result":true}`)
+		return
+	}
+	io.WriteString(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`)
+}
+
+// lastText returns the last message the bot sent or edited
+func (f *fakeTelegram) lastText() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.texts) == 0 {
+		return ""
+	}
+	return f.texts[len(f.texts)-1]
+}
+
+// testApp runs an App on db.Memory against a fake Telegram; it is Monday 19 October 2026, 08:00
+func testApp(t *testing.T) (*App, *bot.Bot, *fakeTelegram, *db.Memory) {
+	t.Helper()
+	now := func() time.Time { return time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC) }
+
+	telegram := &fakeTelegram{}
+	server := httptest.NewServer(telegram)
+	t.Cleanup(server.Close)
+
+	b, err := bot.New("123:test", bot.WithServerURL(server.URL), bot.WithSkipGetMe())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := db.NewMemory(now)
+	if err := store.SeedRooms(context.Background(), []model.Room{{RoomName: "Room A", Capacity: 10}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Booking.WorkdayStart, cfg.Booking.WorkdayEnd = "09:00", "12:00"
+	app, err := NewApp(cfg, store, log.New(io.Discard, "", 0), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, b, telegram, store
+}
+
+func messageUpdate(user *models.User, text string) *models.Update {
+	return &models.Update{Message: &models.Message{
+		ID:   1,
+		From: user,
+		Chat: models.Chat{ID: user.ID, Type: models.ChatTypePrivate},
+		Text: text,
+	}}
+}
+
+func callbackUpdate(user *models.User, action, value string) *models.Update {
+	return &models.Update{CallbackQuery: &models.CallbackQuery{
+		ID:   "1",
+		From: *user,
+		Message: models.MaybeInaccessibleMessage{
+			Type:    models.MaybeInaccessibleMessageTypeMessage,
+			Message: &models.Message{ID: 1, Chat: models.Chat{ID: user.ID, Type: models.ChatTypePrivate}},
+		},
+		Data: callbackData(action, value),
+	}}
+}
+
+func TestBookingFlow(t *testing.T) {
+	ctx := context.Background()
+	app, b, telegram, store := testApp(t)
+	alice := &models.User{ID: 1, FirstName: "Alice", Username: "alice"}
+
+	app.bookHandler(ctx, b, messageUpdate(alice, "/book"))
+	for _, press := range [][2]string{{"date", "2026-10-19"}, {"room", "1"}, {"time", "09:00"}, {"end", "11:00"}} {
+		app.callbackHandler(ctx, b, callbackUpdate(alice, press[0], press[1]))
+	}
+	app.handler(ctx, b, messageUpdate(alice, "Planning"))
+	app.callbackHandler(ctx, b, callbackUpdate(alice, "participants", "skip"))
+	if got := telegram.lastText(); !strings.Contains(got, "Please confirm your booking") {
+		t.Fatalf("before confirming the bot sent %q, want the review screen", got)
+	}
+	app.callbackHandler(ctx, b, callbackUpdate(alice, "confirm", ""))
+
+	if got := telegram.lastText(); !strings.Contains(got, "Booking confirmed") {
+		t.Errorf("last message = %q, want the confirmation", got)
+	}
+	bookings, err := store.GetBookingsByDate(ctx, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookings) != 1 {
+		t.Fatalf("got %d bookings, want 1", len(bookings))
+	}
+	booking := bookings[0]
+	if booking.RoomID != 1 || booking.Topic != "Planning" ||
+		booking.StartTime.Format("15:04") != "09:00" || booking.EndTime.Format("15:04") != "11:00" {
+		t.Errorf("booking = room %d %q %s-%s, want room 1 \"Planning\" 09:00-11:00",
+			booking.RoomID, booking.Topic, booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
+	}
+	if session, _ := app.sessions.GetSession(ctx, alice.ID); session != nil {
+		t.Errorf("session still open at step %q after confirming", session.Step)
+	}
+}
+
+func TestBookingFlowHeldRange(t *testing.T) {
+	ctx := context.Background()
+	app, b, telegram, _ := testApp(t)
+	alice := &models.User{ID: 1, FirstName: "Alice"}
+	bob := &models.User{ID: 2, FirstName: "Bob"}
+
+	// Both pick the same start before either has chosen an end time
+	for _, user := range []*models.User{alice, bob} {
+		app.bookHandler(ctx, b, messageUpdate(user, "/book"))
+		for _, press := range [][2]string{{"date", "2026-10-19"}, {"room", "1"}, {"time", "10:00"}} {
+			app.callbackHandler(ctx, b, callbackUpdate(user, press[0], press[1]))
+		}
+	}
+
+	app.callbackHandler(ctx, b, callbackUpdate(alice, "end", "11:00"))
+	app.callbackHandler(ctx, b, callbackUpdate(bob, "end", "12:00"))
+
+	if got := telegram.lastText(); !strings.Contains(got, "being booked by someone else") {
+		t.Errorf("Bob was sent %q, want to hear the range is held", got)
+	}
+	session, err := app.sessions.GetSession(ctx, bob.ID)
+	if err != nil || session == nil {
+		t.Fatalf("GetSession() = %v, %v", session, err)
+	}
+	if session.EndTime != "" {
+		t.Errorf("Bob's session has end time %q, want none", session.EndTime)
+	}
+}
+
+func TestRescheduleOffersOldRange(t *testing.T) {
+	ctx := context.Background()
+	app, b, telegram, store := testApp(t)
+	alice := &models.User{ID: 1, FirstName: "Alice"}
+	bob := &models.User{ID: 2, FirstName: "Bob"}
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	user, err := store.CreateOrGetUser(ctx, alice.ID, "", "Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	booking := &model.Booking{RoomID: 1, UserID: user.UserID, Topic: "Planning", Date: date,
+		StartTime: date.Add(9 * time.Hour), EndTime: date.Add(10 * time.Hour)}
+	if err := store.CreateBooking(ctx, booking, nil); err != nil {
+		t.Fatal(err)
+	}
+	app.callbackHandler(ctx, b, callbackUpdate(bob, "waitlist", strconv.Itoa(booking.BookingID)))
+
+	app.completeReschedule(ctx, b, alice.ID, alice, &state.BookingSession{
+		UserID: alice.ID, RoomID: 1, RoomName: "Room A", Date: date,
+		StartTime: "11:00", EndTime: "12:00", Topic: "Planning", BookingID: booking.BookingID,
+	})
+
+	if got := telegram.lastText(); !strings.Contains(got, "09:00-10:00 is free") {
+		t.Errorf("last message = %q, want the old range offered to Bob", got)
+	}
+}
+
+// waitlistOffer books 09:00-10:00 for Carol, queues Bob for it and cancels it,
+// so Bob is sent an offer. It returns the offer's waitlist ID.
+func waitlistOffer(t *testing.T, app *App, b *bot.Bot, store *db.Memory, bob *models.User) int {
+	t.Helper()
+	ctx := context.Background()
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	carol, err := store.CreateOrGetUser(ctx, 3, "", "Carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	booking := &model.Booking{RoomID: 1, UserID: carol.UserID, Topic: "Planning", Date: date,
+		StartTime: date.Add(9 * time.Hour), EndTime: date.Add(10 * time.Hour)}
+	if err := store.CreateBooking(ctx, booking, nil); err != nil {
+		t.Fatal(err)
+	}
+	waiter, err := store.CreateOrGetUser(ctx, bob.ID, "", bob.FirstName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	booking, err = store.GetBookingByID(ctx, booking.BookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := bookingSlot(*booking)
+	entry.UserID = waiter.UserID
+	if _, err := store.JoinWaitlist(ctx, &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.CancelBooking(ctx, booking.BookingID, carol.UserID); err != nil {
+		t.Fatal(err)
+	}
+	app.offerWaitlist(ctx, b, bookingSlot(*booking))
+	return entry.WaitlistID
+}
+
+func TestWaitlistOfferHeldWhileBooking(t *testing.T) {
+	ctx := context.Background()
+	app, b, telegram, store := testApp(t)
+	bob := &models.User{ID: 2, FirstName: "Bob"}
+
+	// Bob is in the middle of booking something else when the offer arrives
+	app.bookHandler(ctx, b, messageUpdate(bob, "/book"))
+	for _, press := range [][2]string{{"date", "2026-10-19"}, {"room", "1"}, {"time", "11:00"}, {"end", "12:00"}} {
+		app.callbackHandler(ctx, b, callbackUpdate(bob, press[0], press[1]))
+	}
+	waitlistID := waitlistOffer(t, app, b, store, bob)
+	if got := telegram.lastText(); !strings.Contains(got, "09:00-10:00 is free") {
+		t.Fatalf("last message = %q, want the offer", got)
+	}
+
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	for _, span := range [][2]string{{"09:00", "10:00"}, {"11:00", "12:00"}} {
+		if _, held, err := app.holds.HeldBy(ctx, 1, date, span[0], span[1]); err != nil || !held {
+			t.Errorf("HeldBy(%s-%s) = %v, %v, want held", span[0], span[1], held, err)
+		}
+	}
+
+	app.callbackHandler(ctx, b, callbackUpdate(bob, "waitlist_claim", strconv.Itoa(waitlistID)))
+	session, err := app.sessions.GetSession(ctx, bob.ID)
+	if err != nil || session == nil {
+		t.Fatalf("GetSession() = %v, %v", session, err)
+	}
+	if session.StartTime != "09:00" || session.Step != state.StepEnterTopic {
+		t.Errorf("session at %s step %q, want 09:00 at the topic", session.StartTime, session.Step)
+	}
+	if holder, held, _ := app.holds.HeldBy(ctx, 1, date, "09:00", "10:00"); !held || holder != bob.ID {
+		t.Errorf("09:00-10:00 held by %d (%v), want Bob", holder, held)
+	}
+}
+
+func TestWaitlistClaimTaken(t *testing.T) {
+	ctx := context.Background()
+	app, b, telegram, store := testApp(t)
+	alice := &models.User{ID: 1, FirstName: "Alice"}
+	bob := &models.User{ID: 2, FirstName: "Bob"}
+
+	waitlistID := waitlistOffer(t, app, b, store, bob)
+
+	// The offer's hold is gone and Alice has reserved the range meanwhile
+	app.holds.Release(ctx, offerHolder(waitlistID))
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	if held, err := app.holds.Hold(ctx, 1, date, "09:00", "10:00", alice.ID, time.Minute); err != nil || !held {
+		t.Fatalf("Hold() = %v, %v", held, err)
+	}
+
+	app.callbackHandler(ctx, b, callbackUpdate(bob, "waitlist_claim", strconv.Itoa(waitlistID)))
+	if got := telegram.lastText(); !strings.Contains(got, "someone else has taken") {
+		t.Errorf("Bob was sent %q, want to hear the slot is gone", got)
+	}
+	if session, _ := app.sessions.GetSession(ctx, bob.ID); session != nil {
+		t.Errorf("Bob has a session at step %q, want none", session.Step)
+	}
+}
+
+func TestParticipantContact(t *testing.T) {
+	ctx := context.Background()
+	app, b, _, store := testApp(t)
+	alice := &models.User{ID: 1, FirstName: "Alice"}
+	if _, err := store.CreateOrGetUser(ctx, 2, "bob", "Bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	app.bookHandler(ctx, b, messageUpdate(alice, "/book"))
+	for _, press := range [][2]string{{"date", "2026-10-19"}, {"room", "1"}, {"time", "09:00"}, {"end", "10:00"}} {
+		app.callbackHandler(ctx, b, callbackUpdate(alice, press[0], press[1]))
+	}
+	app.handler(ctx, b, messageUpdate(alice, "Planning"))
+
+	for _, contact := range []*models.Contact{
+		{FirstName: "Bob", UserID: 2},
+		{FirstName: "Carol", UserID: 3},
+		{FirstName: "Dave", PhoneNumber: "+100"},
+	} {
+		update := messageUpdate(alice, "")
+		update.Message.Contact = contact
+		app.handler(ctx, b, update)
+	}
+
+	session, err := app.sessions.GetSession(ctx, alice.ID)
+	if err != nil || session == nil {
+		t.Fatalf("GetSession() = %v, %v", session, err)
+	}
+	if !slices.Equal(session.Participants, []string{"Bob", "Carol", "Dave"}) {
+		t.Errorf("participants = %v, want Bob, Carol and Dave", session.Participants)
+	}
+	if len(session.ParticipantUsers) != 1 || session.ParticipantUsers["Bob"] == 0 {
+		t.Errorf("linked users = %v, want only Bob", session.ParticipantUsers)
+	}
+	if _, err := store.GetUserByTelegramID(ctx, 3); err == nil {
+		t.Error("sharing a contact created a user for telegram ID 3")
+	}
+}
@@ -0,0 +1,705 @@
+// booking.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/service"
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Fields that can be changed from the review screen, and the step that asks for each
+var editSteps = map[string]string{
+	"date":         state.StepSelectDate,
+	"room":         state.StepSelectRoom,
+	"time":         state.StepSelectTime,
+	"topic":        state.StepEnterTopic,
+	"participants": state.StepEnterParticipants,
+	"repeat":       state.StepSelectRepeat,
+}
+
+// showStep renders the prompt for the session's current step. It edits
+// messageID in place when set, otherwise it sends a new message.
+// notice, if not empty, is shown above the prompt.
+func (a *App) showStep(ctx context.Context, b *bot.Bot, chatID int64, messageID int, session *state.BookingSession, notice string) {
+	var text string
+	var parseMode models.ParseMode
+	var rows [][]models.InlineKeyboardButton
+
+	switch session.Step {
+	case state.StepSelectDate:
+		month := a.now()
+		if !session.Date.IsZero() {
+			month = session.Date
+		}
+		text = "📅 Please select a date:"
+		rows = calendarKeyboard(month, a.now()).InlineKeyboard
+
+	case state.StepSelectRoom:
+		rooms, err := a.store.GetAllActiveRooms(ctx)
+		if err != nil {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "Sorry, unable to retrieve rooms. Please try again later.",
+			})
+			a.logger.Printf("Error getting rooms: %v", err)
+			return
+		}
+		text = fmt.Sprintf("📅 %s\nPlease select a room:", session.Date.Format("02 Jan 2006"))
+		rows = roomKeyboard(rooms)
+
+	case state.StepSelectTime, state.StepSelectEndTime:
+		schedule, err := a.roomSchedule(ctx, session)
+		if err != nil {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "Sorry, unable to retrieve schedule. Please try again later.",
+			})
+			a.logger.Printf("Error getting room schedule: %v", err)
+			return
+		}
+
+		if session.Step == state.StepSelectTime {
+			rows = timeKeyboard(schedule)
+			text = fmt.Sprintf("🏢 %s\n📅 %s\nPlease select a start time:", session.RoomName, session.Date.Format("02 Jan 2006"))
+			if len(rows) == 0 {
+				text = fmt.Sprintf("🏢 %s is fully booked on %s. Please go back and choose another room.", session.RoomName, session.Date.Format("02 Jan 2006"))
+			}
+			rows = append(rows, waitlistKeyboard(schedule)...)
+		} else {
+			rows = timeButtons("end", endTimes(schedule, session.StartTime))
+			text = fmt.Sprintf("🏢 %s\n⏰ Starts at %s\nPlease select the end time:", session.RoomName, session.StartTime)
+		}
+
+	case state.StepEnterTopic:
+		text = fmt.Sprintf("🏢 %s\n📅 %s\n⏰ %s - %s\n\nPlease type the meeting topic:",
+			session.RoomName, session.Date.Format("02 Jan 2006"), session.StartTime, session.EndTime)
+		if session.Topic != "" {
+			text += fmt.Sprintf("\n(currently: %s)", session.Topic)
+		}
+
+	case state.StepEnterParticipants:
+		text = "Please type the participant names separated by commas, or share their contacts.\n" +
+			"Use @username for people on Telegram so they are told about the meeting."
+		if len(session.Participants) > 0 {
+			text += fmt.Sprintf("\n(currently: %s)", strings.Join(session.Participants, ", "))
+			rows = append(rows, []models.InlineKeyboardButton{
+				{Text: "✅ Done", CallbackData: callbackData("participants", "done")},
+			})
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: "⏭ Skip", CallbackData: callbackData("participants", "skip")},
+		})
+
+	case state.StepSelectRepeat:
+		text = "🔁 How often should this meeting repeat?"
+		if session.Repeat == model.FrequencyWeekly {
+			text += "\nPick the weekdays, then press Next:"
+		}
+		rows = repeatKeyboard(session)
+
+	case state.StepEnterRepeatEnd:
+		series := model.BookingSeries{Frequency: session.Repeat, Weekdays: session.RepeatDays, StartDate: session.Date}
+		text = fmt.Sprintf("🔁 %s from %s\nHow many times should it happen? Press a button, or type a number or an end date (YYYY-MM-DD):",
+			a.service.FormatRecurrence(series), session.Date.Format("02 Jan 2006"))
+		rows = repeatEndKeyboard()
+
+	case state.StepReview:
+		booking, err := sessionBooking(session)
+		if err != nil {
+			a.logger.Printf("Invalid booking session: %v", err)
+			return
+		}
+		parseMode = models.ParseModeMarkdown
+		rows = [][]models.InlineKeyboardButton{
+			{
+				{Text: "✏ Date", CallbackData: callbackData("edit", "date")},
+				{Text: "✏ Room", CallbackData: callbackData("edit", "room")},
+				{Text: "✏ Time", CallbackData: callbackData("edit", "time")},
+			},
+		}
+		// Rescheduling only moves the booking; the topic and participants stay as they are
+		if session.BookingID != 0 {
+			booking.BookingID = session.BookingID
+			text = a.service.FormatRescheduleSummary(*booking)
+		} else {
+			text = a.service.FormatBookingSummary(*booking)
+			if session.Repeat != "" {
+				text += fmt.Sprintf("   🔁 %s\n", bot.EscapeMarkdown(a.service.FormatRecurrence(sessionSeries(session, booking))))
+			}
+			rows = append(rows, []models.InlineKeyboardButton{
+				{Text: "✏ Topic", CallbackData: callbackData("edit", "topic")},
+				{Text: "✏ Participants", CallbackData: callbackData("edit", "participants")},
+				{Text: "🔁 Repeat", CallbackData: callbackData("edit", "repeat")},
+			})
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: "✅ Confirm", CallbackData: callbackData("confirm", "")},
+			{Text: "🗑 Discard", CallbackData: callbackData("discard", "")},
+		})
+
+	default:
+		a.logger.Printf("Unknown booking step: %q", session.Step)
+		return
+	}
+
+	if notice != "" {
+		if parseMode == models.ParseModeMarkdown {
+			notice = bot.EscapeMarkdown(notice)
+		}
+		text = notice + "\n" + text
+	}
+
+	// Every step after the first can go back one step
+	if session.Step != state.StepSelectDate {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: "⬅ Back", CallbackData: callbackData("back", session.Step)},
+		})
+	}
+	keyboard := &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+
+	if messageID != 0 {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      chatID,
+			MessageID:   messageID,
+			Text:        text,
+			ParseMode:   parseMode,
+			ReplyMarkup: keyboard,
+		})
+		return
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   parseMode,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// sessionBooking builds the booking a finished session describes.
+// UserID is left for the caller to fill in.
+func sessionBooking(session *state.BookingSession) (*model.Booking, error) {
+	date := session.Date
+	startTime, err := time.ParseInLocation("15:04", session.StartTime, date.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q: %w", session.StartTime, err)
+	}
+	endTime, err := time.ParseInLocation("15:04", session.EndTime, date.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time %q: %w", session.EndTime, err)
+	}
+
+	return &model.Booking{
+		RoomID:       session.RoomID,
+		RoomName:     session.RoomName,
+		Topic:        session.Topic,
+		Date:         date,
+		StartTime:    time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, date.Location()),
+		EndTime:      time.Date(date.Year(), date.Month(), date.Day(), endTime.Hour(), endTime.Minute(), 0, 0, date.Location()),
+		Participants: session.Participants,
+	}, nil
+}
+
+// sessionParticipants pairs each participant name with the user it was linked to, if any
+func sessionParticipants(session *state.BookingSession) []model.Participants {
+	var participants []model.Participants
+	for _, name := range session.Participants {
+		participant := model.Participants{Name: name}
+		if userID, ok := session.ParticipantUsers[name]; ok {
+			participant.UserID = &userID
+		}
+		participants = append(participants, participant)
+	}
+	return participants
+}
+
+// advance moves the session on to step, or straight back to the review
+// screen when the user was only changing one field.
+func advance(session *state.BookingSession, step string) {
+	if session.Editing {
+		step = state.StepReview
+	}
+	session.Step = step
+	if step == state.StepReview {
+		session.Editing = false
+	}
+}
+
+// previousStep returns the step before the session's current one.
+// While editing the topic or participants, going back returns to the review screen.
+func previousStep(session *state.BookingSession) string {
+	switch session.Step {
+	case state.StepSelectRoom:
+		return state.StepSelectDate
+	case state.StepSelectTime:
+		return state.StepSelectRoom
+	case state.StepSelectEndTime:
+		return state.StepSelectTime
+	case state.StepEnterTopic:
+		if session.Editing {
+			return state.StepReview
+		}
+		return state.StepSelectEndTime
+	case state.StepEnterParticipants:
+		if session.Editing {
+			return state.StepReview
+		}
+		return state.StepEnterTopic
+	case state.StepReview:
+		if session.BookingID != 0 {
+			return state.StepSelectEndTime
+		}
+		return state.StepEnterParticipants
+	case state.StepSelectRepeat:
+		return state.StepReview
+	case state.StepEnterRepeatEnd:
+		return state.StepSelectRepeat
+	}
+	return ""
+}
+
+// reopenTimes releases the user's hold when the session moves back to a step
+// that picks the room or time, so their own range shows as free again.
+func (a *App) reopenTimes(ctx context.Context, userID int64, step string) {
+	switch step {
+	case state.StepSelectDate, state.StepSelectRoom, state.StepSelectTime, state.StepSelectEndTime:
+		if err := a.holds.Release(ctx, userID); err != nil {
+			a.logger.Printf("Error releasing hold: %v", err)
+		}
+	}
+}
+
+// backCallback returns to the previous step. The value is the step the
+// button was shown on, so stale buttons from earlier messages are ignored.
+func (a *App) backCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, value)
+	if session == nil {
+		return
+	}
+
+	step := previousStep(session)
+	if step == "" {
+		return
+	}
+
+	a.reopenTimes(ctx, query.From.ID, step)
+	// Leaving the repeat steps before the series length is set drops the repeat
+	if step == state.StepReview && (session.Step == state.StepSelectRepeat || session.Step == state.StepEnterRepeatEnd) && !hasRepeatEnd(session) {
+		session.Repeat = ""
+		session.RepeatDays = nil
+	}
+	session.Step = step
+	if step == state.StepReview {
+		session.Editing = false
+	}
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// editCallback reopens one field from the review screen
+func (a *App) editCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepReview)
+	if session == nil {
+		return
+	}
+
+	step, ok := editSteps[value]
+	if !ok || (session.BookingID != 0 && step != state.StepSelectDate && step != state.StepSelectRoom && step != state.StepSelectTime) {
+		a.logger.Printf("Invalid edit callback: %q", value)
+		return
+	}
+
+	a.reopenTimes(ctx, query.From.ID, step)
+	session.Step = step
+	session.Editing = true
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// confirmCallback books the reviewed session
+func (a *App) confirmCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery) {
+	session := a.activeSession(ctx, b, query, state.StepReview)
+	if session == nil {
+		return
+	}
+
+	// A series needs a length; CreateSeries would be refused without one
+	if session.Repeat != "" && !hasRepeatEnd(session) {
+		session.Step = state.StepEnterRepeatEnd
+		if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+			return
+		}
+		a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "Please choose how long the meeting repeats.")
+		return
+	}
+
+	// Drop the review buttons so the booking cannot be submitted twice
+	b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:    callbackChatID(query),
+		MessageID: callbackMessageID(query),
+	})
+
+	if session.BookingID != 0 {
+		a.completeReschedule(ctx, b, callbackChatID(query), &query.From, session)
+		return
+	}
+	if session.Repeat != "" {
+		a.completeSeries(ctx, b, callbackChatID(query), &query.From, session)
+		return
+	}
+	a.completeBooking(ctx, b, callbackChatID(query), &query.From, session)
+}
+
+// discardCallback throws the reviewed session away without saving it
+func (a *App) discardCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery) {
+	session := a.activeSession(ctx, b, query, state.StepReview)
+	if session == nil {
+		return
+	}
+
+	a.clearSession(ctx, query.From.ID)
+
+	text := "🗑 Booking discarded. Type /book to start again."
+	if session.BookingID != 0 {
+		text = "🗑 Reschedule discarded. Your booking has not been changed."
+	}
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    callbackChatID(query),
+		MessageID: callbackMessageID(query),
+		Text:      text,
+	})
+}
+
+// roomKeyboard builds one button per active room, two per row
+func roomKeyboard(rooms []model.Room) [][]models.InlineKeyboardButton {
+	var rows [][]models.InlineKeyboardButton
+	var row []models.InlineKeyboardButton
+	for _, room := range rooms {
+		label := fmt.Sprintf("🏢 %s (👥 %d)", room.RoomName, room.Capacity)
+		row = append(row, models.InlineKeyboardButton{Text: label, CallbackData: callbackData("room", strconv.Itoa(room.RoomID))})
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (a *App) roomCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepSelectRoom)
+	if session == nil {
+		return
+	}
+	chatID := callbackChatID(query)
+
+	roomID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid room callback: %q", value)
+		return
+	}
+
+	room, err := a.store.GetRoomByID(ctx, roomID)
+	if err != nil || room.Status != "ACTIVE" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, that room is not available.",
+		})
+		a.logger.Printf("Error getting room %d: %v", roomID, err)
+		return
+	}
+
+	session.RoomID = room.RoomID
+	session.RoomName = room.RoomName
+	session.Step = state.StepSelectTime
+	if !a.saveSession(ctx, b, chatID, query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, chatID, callbackMessageID(query), session, "")
+}
+
+// roomSchedule returns the schedule of the session's room and date. When
+// rescheduling, the booking being moved counts as free so it can shift within
+// its own time, apart from slots that have already started.
+func (a *App) roomSchedule(ctx context.Context, session *state.BookingSession) (*model.RoomSchedule, error) {
+	schedule, err := a.service.GetRoomSchedule(ctx, session.RoomID, session.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.BookingID != 0 {
+		for i := range schedule.TimeSlots {
+			slot := &schedule.TimeSlots[i]
+			if slot.Booking != nil && slot.Booking.BookingID == session.BookingID {
+				slot.Booking = nil
+				if slot.StartTime.Before(a.now()) {
+					slot.IsPast = true
+				} else {
+					slot.IsFree = true
+				}
+			}
+		}
+	}
+
+	return schedule, nil
+}
+
+// timeKeyboard builds one start-time button per free slot, three per row
+func timeKeyboard(schedule *model.RoomSchedule) [][]models.InlineKeyboardButton {
+	var starts []string
+	for _, slot := range schedule.TimeSlots {
+		if slot.IsFree {
+			starts = append(starts, slot.StartTime.Format("15:04"))
+		}
+	}
+	return timeButtons("time", starts)
+}
+
+// endTimes lists every end time reachable from startTime through consecutive free slots
+func endTimes(schedule *model.RoomSchedule, startTime string) []string {
+	var ends []string
+	started := false
+	for _, slot := range schedule.TimeSlots {
+		if slot.StartTime.Format("15:04") == startTime {
+			started = true
+		}
+		if !started {
+			continue
+		}
+		if !slot.IsFree {
+			break
+		}
+		ends = append(ends, slot.EndTime.Format("15:04"))
+	}
+	return ends
+}
+
+func timeButtons(action string, times []string) [][]models.InlineKeyboardButton {
+	var rows [][]models.InlineKeyboardButton
+	var row []models.InlineKeyboardButton
+	for _, t := range times {
+		row = append(row, models.InlineKeyboardButton{Text: "⏰ " + t, CallbackData: callbackData(action, t)})
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// timeCallback stores the start time and asks how long the meeting runs
+func (a *App) timeCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, startTime string) {
+	session := a.activeSession(ctx, b, query, state.StepSelectTime)
+	if session == nil {
+		return
+	}
+
+	schedule, err := a.roomSchedule(ctx, session)
+	if err != nil {
+		a.logger.Printf("Error getting room schedule: %v", err)
+		return
+	}
+
+	if len(endTimes(schedule, startTime)) == 0 {
+		a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session,
+			fmt.Sprintf("⏳ %s is no longer free.", startTime))
+		return
+	}
+
+	session.StartTime = startTime
+	session.Step = state.StepSelectEndTime
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// endCallback holds the chosen range and moves on to the topic
+func (a *App) endCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, endTime string) {
+	session := a.activeSession(ctx, b, query, state.StepSelectEndTime)
+	if session == nil {
+		return
+	}
+
+	schedule, err := a.roomSchedule(ctx, session)
+	if err != nil {
+		a.logger.Printf("Error getting room schedule: %v", err)
+		return
+	}
+
+	// Reserve the range so nobody else can pick it while the user types the details
+	held := false
+	if slices.Contains(endTimes(schedule, session.StartTime), endTime) {
+		held, err = a.holds.Hold(ctx, session.RoomID, session.Date, session.StartTime, endTime, query.From.ID, a.config.Booking.HoldTTL())
+		if err != nil {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: callbackChatID(query),
+				Text:   "Sorry, something went wrong. Please try again later.",
+			})
+			a.logger.Printf("Error holding slot: %v", err)
+			return
+		}
+	}
+	if !held {
+		session.Step = state.StepSelectTime
+		if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+			return
+		}
+
+		a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session,
+			fmt.Sprintf("⏳ %s-%s is being booked by someone else.", session.StartTime, endTime))
+		return
+	}
+
+	session.EndTime = endTime
+	advance(session, state.StepEnterTopic)
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+func (a *App) participantsCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepEnterParticipants)
+	if session == nil {
+		return
+	}
+
+	if value == "skip" {
+		session.Participants = nil
+		session.ParticipantUsers = nil
+	}
+
+	advance(session, state.StepReview)
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// completeBooking writes the session to the database and ends the conversation.
+func (a *App) completeBooking(ctx context.Context, b *bot.Bot, chatID int64, from *models.User, session *state.BookingSession) {
+	fullName := strings.TrimSpace(from.FirstName + " " + from.LastName)
+	user, err := a.store.CreateOrGetUser(ctx, from.ID, from.Username, fullName)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	booking, err := sessionBooking(session)
+	if err != nil {
+		a.logger.Printf("Invalid booking session: %v", err)
+		return
+	}
+	booking.UserID = user.UserID
+	booking.FullName = user.FullName
+
+	err = a.store.CreateBooking(ctx, booking, sessionParticipants(session))
+
+	// The session and its hold are finished either way; a failed insert means starting over
+	a.clearSession(ctx, from.ID)
+
+	if errors.Is(err, db.ErrSlotTaken) {
+		a.slotTaken(ctx, b, chatID, from.ID, session)
+		return
+	}
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, unable to create your booking. Type /book to try again.",
+		})
+		a.logger.Printf("Error creating booking: %v", err)
+		return
+	}
+
+	message := "✅ *Booking confirmed\\!*\n\n"
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(session.RoomName))
+	message += fmt.Sprintf("📅 %s\n", booking.Date.Format("02 Jan 2006"))
+	message += fmt.Sprintf("⏰ %s \\- %s\n", session.StartTime, session.EndTime)
+	message += fmt.Sprintf("📝 %s\n", bot.EscapeMarkdown(session.Topic))
+	if len(session.Participants) > 0 {
+		message += fmt.Sprintf("👥 %s\n", bot.EscapeMarkdown(strings.Join(session.Participants, ", ")))
+	}
+	message += fmt.Sprintf("🔖 ID: `%d`", booking.BookingID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      message,
+		ParseMode: models.ParseModeMarkdown,
+	})
+
+	a.notifyParticipants(ctx, b, booking.BookingID, from.ID, a.service.FormatParticipantNotice(service.NoticeAdded, *booking))
+}
+
+// slotTaken tells the user someone else booked the slot first, shows the
+// refreshed timetable and lets them pick another time in the same room.
+// The topic and participants are kept, so a new time goes straight back to review.
+func (a *App) slotTaken(ctx context.Context, b *bot.Bot, chatID int64, userID int64, session *state.BookingSession) {
+	message := fmt.Sprintf("😕 Sorry, %s %s-%s was just taken by someone else.", session.RoomName, session.StartTime, session.EndTime)
+	if session.BookingID != 0 {
+		message += "\nYour booking has not been changed."
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   message,
+	})
+
+	schedules, err := a.service.GenerateTimetableForDate(ctx, session.Date)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, unable to retrieve schedule. Type /book to try again.",
+		})
+		a.logger.Printf("Error getting timetable: %v", err)
+		return
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      a.service.FormatTimetableMessage(schedules),
+		ParseMode: models.ParseModeMarkdown,
+	})
+
+	retry := *session
+	retry.Step = state.StepSelectTime
+	retry.StartTime = ""
+	retry.EndTime = ""
+	retry.Editing = true
+	if !a.saveSession(ctx, b, chatID, userID, &retry) {
+		return
+	}
+
+	a.showStep(ctx, b, chatID, 0, &retry, "")
+}
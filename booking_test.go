@@ -0,0 +1,133 @@
+// booking_test.go
+
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/state"
+)
+
+func TestPreviousStep(t *testing.T) {
+	tests := []struct {
+		name    string
+		session state.BookingSession
+		want    string
+	}{
+		{"room", state.BookingSession{Step: state.StepSelectRoom}, state.StepSelectDate},
+		{"start time", state.BookingSession{Step: state.StepSelectTime}, state.StepSelectRoom},
+		{"end time", state.BookingSession{Step: state.StepSelectEndTime}, state.StepSelectTime},
+		{"topic", state.BookingSession{Step: state.StepEnterTopic}, state.StepSelectEndTime},
+		{"topic while editing", state.BookingSession{Step: state.StepEnterTopic, Editing: true}, state.StepReview},
+		{"participants", state.BookingSession{Step: state.StepEnterParticipants}, state.StepEnterTopic},
+		{"participants while editing", state.BookingSession{Step: state.StepEnterParticipants, Editing: true}, state.StepReview},
+		{"review", state.BookingSession{Step: state.StepReview}, state.StepEnterParticipants},
+		{"review of a reschedule", state.BookingSession{Step: state.StepReview, BookingID: 7}, state.StepSelectEndTime},
+		{"repeat", state.BookingSession{Step: state.StepSelectRepeat}, state.StepReview},
+		{"repeat end", state.BookingSession{Step: state.StepEnterRepeatEnd}, state.StepSelectRepeat},
+		{"date has nothing before it", state.BookingSession{Step: state.StepSelectDate}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previousStep(&tt.session); got != tt.want {
+				t.Errorf("previousStep() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	tests := []struct {
+		name        string
+		editing     bool
+		step        string
+		want        string
+		wantEditing bool
+	}{
+		{"next step", false, state.StepEnterParticipants, state.StepEnterParticipants, false},
+		{"to review", false, state.StepReview, state.StepReview, false},
+		{"editing returns to review", true, state.StepEnterParticipants, state.StepReview, false},
+		{"editing finished at review", true, state.StepReview, state.StepReview, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := state.BookingSession{Step: state.StepEnterTopic, Editing: tt.editing}
+			advance(&session, tt.step)
+			if session.Step != tt.want || session.Editing != tt.wantEditing {
+				t.Errorf("advance() = step %q editing %v, want step %q editing %v", session.Step, session.Editing, tt.want, tt.wantEditing)
+			}
+		})
+	}
+}
+
+func TestEndTimes(t *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	slot := func(hour int, free bool) model.TimeSlot {
+		start := day.Add(time.Duration(hour) * time.Hour)
+		return model.TimeSlot{StartTime: start, EndTime: start.Add(time.Hour), IsFree: free}
+	}
+	schedule := &model.RoomSchedule{
+		TimeSlots: []model.TimeSlot{slot(9, true), slot(10, true), slot(11, false), slot(12, true)},
+	}
+
+	tests := []struct {
+		name      string
+		startTime string
+		want      []string
+	}{
+		{"runs until the next booking", "09:00", []string{"10:00", "11:00"}},
+		{"single slot before a booking", "10:00", []string{"11:00"}},
+		{"last slot of the day", "12:00", []string{"13:00"}},
+		{"booked start", "11:00", nil},
+		{"unknown start", "08:00", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := endTimes(schedule, tt.startTime); !slices.Equal(got, tt.want) {
+				t.Errorf("endTimes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionBooking(t *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{"times on the session's date", "09:30", "11:00", day.Add(9*time.Hour + 30*time.Minute), day.Add(11 * time.Hour), false},
+		{"bad start time", "9am", "11:00", time.Time{}, time.Time{}, true},
+		{"missing end time", "09:00", "", time.Time{}, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := state.BookingSession{RoomID: 1, RoomName: "Room A", Date: day, StartTime: tt.start, EndTime: tt.end, Topic: "Planning"}
+			booking, err := sessionBooking(&session)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sessionBooking() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !booking.StartTime.Equal(tt.wantStart) || !booking.EndTime.Equal(tt.wantEnd) {
+				t.Errorf("sessionBooking() = %s-%s, want %s-%s", booking.StartTime, booking.EndTime, tt.wantStart, tt.wantEnd)
+			}
+			if booking.RoomID != 1 || booking.Topic != "Planning" {
+				t.Errorf("sessionBooking() = %+v, want room 1 and topic Planning", booking)
+			}
+		})
+	}
+}
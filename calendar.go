@@ -0,0 +1,143 @@
+// calendar.go
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// calendarKeyboard renders a month view with prev/next navigation.
+// Days before now are greyed out and cannot be selected.
+func calendarKeyboard(month, now time.Time) *models.InlineKeyboardMarkup {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	var rows [][]models.InlineKeyboardButton
+
+	// Header: ◀ October 2026 ▶ (no going back before the current month)
+	prev := models.InlineKeyboardButton{Text: " ", CallbackData: callbackData("ignore", "")}
+	if first.After(today) {
+		prev = models.InlineKeyboardButton{Text: "◀", CallbackData: callbackData("calendar", first.AddDate(0, -1, 0).Format("2006-01"))}
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		prev,
+		{Text: first.Format("January 2006"), CallbackData: callbackData("ignore", "")},
+		{Text: "▶", CallbackData: callbackData("calendar", first.AddDate(0, 1, 0).Format("2006-01"))},
+	})
+
+	var weekdays []models.InlineKeyboardButton
+	for _, day := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		weekdays = append(weekdays, models.InlineKeyboardButton{Text: day, CallbackData: callbackData("ignore", "")})
+	}
+	rows = append(rows, weekdays)
+
+	// Pad the first week so days line up under Monday
+	offset := (int(first.Weekday()) + 6) % 7
+	week := make([]models.InlineKeyboardButton, 0, 7)
+	for i := 0; i < offset; i++ {
+		week = append(week, models.InlineKeyboardButton{Text: " ", CallbackData: callbackData("ignore", "")})
+	}
+
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		button := models.InlineKeyboardButton{
+			Text:         strconv.Itoa(day.Day()),
+			CallbackData: callbackData("date", day.Format("2006-01-02")),
+		}
+		if day.Before(today) {
+			button = models.InlineKeyboardButton{Text: "·" + strconv.Itoa(day.Day()) + "·", CallbackData: callbackData("ignore", "")}
+		} else if day.Equal(today) {
+			button.Text = "[" + button.Text + "]"
+		}
+
+		week = append(week, button)
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]models.InlineKeyboardButton, 0, 7)
+		}
+	}
+
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, models.InlineKeyboardButton{Text: " ", CallbackData: callbackData("ignore", "")})
+		}
+		rows = append(rows, week)
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// calendarCallback switches the calendar to another month
+func (a *App) calendarCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	if a.activeSession(ctx, b, query, state.StepSelectDate) == nil {
+		return
+	}
+
+	month, err := time.ParseInLocation("2006-01", value, time.Local)
+	if err != nil {
+		a.logger.Printf("Invalid calendar callback: %q", value)
+		return
+	}
+
+	b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:      callbackChatID(query),
+		MessageID:   callbackMessageID(query),
+		ReplyMarkup: calendarKeyboard(month, a.now()),
+	})
+}
+
+// dateCallback stores the chosen date, shows its timetable and moves on to room selection
+func (a *App) dateCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepSelectDate)
+	if session == nil {
+		return
+	}
+	chatID := callbackChatID(query)
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		a.logger.Printf("Invalid date callback: %q", value)
+		return
+	}
+
+	now := a.now()
+	if date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())) {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "You cannot book a date in the past. Please select another date.",
+		})
+		return
+	}
+
+	schedules, err := a.service.GenerateTimetableForDate(ctx, date)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, unable to retrieve schedule. Please try again later.",
+		})
+		a.logger.Printf("Error getting timetable: %v", err)
+		return
+	}
+
+	session.Date = date
+	session.Step = state.StepSelectRoom
+	if !a.saveSession(ctx, b, chatID, query.From.ID, session) {
+		return
+	}
+
+	// Replace the calendar with the timetable for the chosen date
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: callbackMessageID(query),
+		Text:      a.service.FormatTimetableMessage(schedules),
+		ParseMode: models.ParseModeMarkdown,
+	})
+
+	a.showStep(ctx, b, chatID, 0, session, "")
+}
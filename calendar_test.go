@@ -0,0 +1,81 @@
+// calendar_test.go
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// dayButtons maps each day of the month to its button, by the number on it
+func dayButtons(keyboard *models.InlineKeyboardMarkup) map[int]models.InlineKeyboardButton {
+	buttons := make(map[int]models.InlineKeyboardButton)
+	for _, row := range keyboard.InlineKeyboard[2:] {
+		for _, button := range row {
+			if day, err := strconv.Atoi(strings.Trim(button.Text, "·[]")); err == nil {
+				buttons[day] = button
+			}
+		}
+	}
+	return buttons
+}
+
+func TestCalendarKeyboard(t *testing.T) {
+	// Monday 19 October 2026, mid-afternoon
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	november := october.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name     string
+		month    time.Time
+		wantPrev bool
+		wantDays map[int]string // callback data expected for some days
+	}{
+		{
+			name:     "this month",
+			month:    october,
+			wantPrev: false,
+			wantDays: map[int]string{
+				18: callbackData("ignore", ""),
+				19: callbackData("date", "2026-10-19"),
+				31: callbackData("date", "2026-10-31"),
+			},
+		},
+		{
+			name:     "next month",
+			month:    november,
+			wantPrev: true,
+			wantDays: map[int]string{1: callbackData("date", "2026-11-01")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyboard := calendarKeyboard(tt.month, now)
+
+			if got := keyboard.InlineKeyboard[0][0].Text == "◀"; got != tt.wantPrev {
+				t.Errorf("previous month button = %v, want %v", got, tt.wantPrev)
+			}
+			for _, row := range keyboard.InlineKeyboard[1:] {
+				if len(row) != 7 {
+					t.Errorf("row %v has %d buttons, want 7", row, len(row))
+				}
+			}
+
+			days := dayButtons(keyboard)
+			if len(days) != tt.month.AddDate(0, 1, -1).Day() {
+				t.Errorf("got %d days, want %d", len(days), tt.month.AddDate(0, 1, -1).Day())
+			}
+			for day, want := range tt.wantDays {
+				if got := days[day].CallbackData; got != want {
+					t.Errorf("day %d callback = %q, want %q", day, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// callback.go
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Callback payloads look like "<action> | <value>", e.g. "room | 2" for the room with room_id 2.
+const callbackSeparator = " | "
+
+func callbackData(action, value string) string {
+	return action + callbackSeparator + value
+}
+
+// callbackHandler routes every inline keyboard press to the handler for its action.
+func (a *App) callbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	if query == nil {
+		return
+	}
+
+	// Always answer so Telegram stops showing the loading spinner on the button
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+	})
+
+	action, value, _ := strings.Cut(query.Data, callbackSeparator)
+
+	switch action {
+	case "ignore":
+		// Calendar padding and headers
+	case "calendar":
+		a.calendarCallback(ctx, b, query, value)
+	case "date":
+		a.dateCallback(ctx, b, query, value)
+	case "room":
+		a.roomCallback(ctx, b, query, value)
+	case "time":
+		a.timeCallback(ctx, b, query, value)
+	case "end":
+		a.endCallback(ctx, b, query, value)
+	case "participants":
+		a.participantsCallback(ctx, b, query, value)
+	case "back":
+		a.backCallback(ctx, b, query, value)
+	case "edit":
+		a.editCallback(ctx, b, query, value)
+	case "confirm":
+		a.confirmCallback(ctx, b, query)
+	case "discard":
+		a.discardCallback(ctx, b, query)
+	case "repeat":
+		a.repeatCallback(ctx, b, query, value)
+	case "repeat_day":
+		a.repeatDayCallback(ctx, b, query, value)
+	case "repeat_next":
+		a.repeatNextCallback(ctx, b, query)
+	case "repeat_count":
+		a.repeatCountCallback(ctx, b, query, value)
+	case "reschedule":
+		a.rescheduleCallback(ctx, b, query, value)
+	case "reschedule_page":
+		a.reschedulePageCallback(ctx, b, query, value)
+	case "waitlist":
+		a.waitlistCallback(ctx, b, query, value)
+	case "waitlist_claim":
+		a.waitlistClaimCallback(ctx, b, query, value)
+	case "waitlist_pass":
+		a.waitlistPassCallback(ctx, b, query, value)
+	case "checkin":
+		a.checkinCallback(ctx, b, query, value)
+	case "cancel":
+		a.cancelCallback(ctx, b, query, value)
+	case "cancel_confirm":
+		a.cancelConfirmCallback(ctx, b, query, value)
+	case "cancel_abort":
+		a.cancelAbortCallback(ctx, b, query)
+	case "cancel_page":
+		a.cancelPageCallback(ctx, b, query, value)
+	default:
+		a.logger.Printf("Unknown callback data: %q", query.Data)
+	}
+}
+
+// callbackChatID returns the chat the pressed button belongs to
+func callbackChatID(query *models.CallbackQuery) int64 {
+	if query.Message.Message != nil {
+		return query.Message.Message.Chat.ID
+	}
+	if query.Message.InaccessibleMessage != nil {
+		return query.Message.InaccessibleMessage.Chat.ID
+	}
+	return query.From.ID
+}
+
+// callbackMessageID returns the message the pressed button is attached to
+func callbackMessageID(query *models.CallbackQuery) int {
+	if query.Message.Message != nil {
+		return query.Message.Message.ID
+	}
+	if query.Message.InaccessibleMessage != nil {
+		return query.Message.InaccessibleMessage.MessageID
+	}
+	return 0
+}
+
+// activeSession returns the user's session if it is at the expected step,
+// otherwise tells the user to start over.
+func (a *App) activeSession(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, step string) *state.BookingSession {
+	session, err := a.loadSession(ctx, query.From.ID)
+	if err != nil {
+		a.logger.Printf("Error getting session: %v", err)
+	}
+	if session == nil || session.Step != step {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "This booking is no longer active. Type /book to start again.",
+		})
+		return nil
+	}
+	return session
+}
@@ -0,0 +1,187 @@
+// cancel.go
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/service"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// cancelKeyboard builds one cancel button per booking on the page
+func cancelKeyboard(bookings []model.Booking, page int) *models.InlineKeyboardMarkup {
+	return bookingListKeyboard(bookings, page, "🗑", "cancel")
+}
+
+// bookingListKeyboard builds one button per booking on the page, labelled with
+// icon and sending action, followed by buttons to the neighbouring pages,
+// which send "<action>_page"
+func bookingListKeyboard(bookings []model.Booking, page int, icon string, action string) *models.InlineKeyboardMarkup {
+	shown, page, pages := service.BookingPage(bookings, page)
+
+	var rows [][]models.InlineKeyboardButton
+	for _, booking := range shown {
+		label := fmt.Sprintf("%s %s %s %s", icon, booking.RoomName, booking.Date.Format("02 Jan"), booking.StartTime.Format("15:04"))
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: label, CallbackData: callbackData(action, strconv.Itoa(booking.BookingID))},
+		})
+	}
+
+	var nav []models.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, models.InlineKeyboardButton{Text: "◀ Previous", CallbackData: callbackData(action+"_page", strconv.Itoa(page-1))})
+	}
+	if page < pages-1 {
+		nav = append(nav, models.InlineKeyboardButton{Text: "Next ▶", CallbackData: callbackData(action+"_page", strconv.Itoa(page+1))})
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// cancelCallback asks the user to confirm cancelling the chosen booking
+func (a *App) cancelCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	bookingID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid cancel callback: %q", value)
+		return
+	}
+
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	booking, err := a.store.GetBookingByID(ctx, bookingID)
+	if err != nil || booking.UserID != user.UserID {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving the booking.",
+		})
+		a.logger.Printf("Error getting booking %d for user %d: %v", bookingID, user.UserID, err)
+		return
+	}
+
+	message := "*Cancel this booking?*\n\n"
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(booking.RoomName))
+	message += fmt.Sprintf("📅 %s\n", booking.Date.Format("02 Jan 2006"))
+	message += fmt.Sprintf("⏰ %s \\- %s\n", booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
+	message += fmt.Sprintf("📝 %s\n", bot.EscapeMarkdown(booking.Topic))
+	message += fmt.Sprintf("🔖 ID: `%d`", booking.BookingID)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    callbackChatID(query),
+		MessageID: callbackMessageID(query),
+		Text:      message,
+		ParseMode: models.ParseModeMarkdown,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: "✅ Yes, cancel it", CallbackData: callbackData("cancel_confirm", value)},
+					{Text: "↩ No, keep it", CallbackData: callbackData("cancel_abort", value)},
+				},
+			},
+		},
+	})
+}
+
+// cancelConfirmCallback cancels the booking and refreshes the list in place
+func (a *App) cancelConfirmCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	bookingID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid cancel callback: %q", value)
+		return
+	}
+
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	// Needed afterwards to tell the participants and offer the freed slot to the waitlist
+	booking, err := a.store.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		a.logger.Printf("Error getting booking %d: %v", bookingID, err)
+	}
+
+	// CancelBooking only touches bookings owned by this user
+	if err := a.store.CancelBooking(ctx, bookingID, user.UserID); err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Unable to cancel this booking. It may already be cancelled.",
+		})
+		a.logger.Printf("Error cancelling booking %d: %v", bookingID, err)
+	} else if booking != nil {
+		a.notifyParticipants(ctx, b, bookingID, query.From.ID, a.service.FormatParticipantNotice(service.NoticeCancelled, *booking))
+		a.offerWaitlist(ctx, b, bookingSlot(*booking))
+	}
+
+	a.refreshCancelList(ctx, b, query, user.UserID, 0)
+}
+
+// cancelAbortCallback returns to the booking list without changes
+func (a *App) cancelAbortCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery) {
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	a.refreshCancelList(ctx, b, query, user.UserID, 0)
+}
+
+// cancelPageCallback shows another page of the booking list
+func (a *App) cancelPageCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	page, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid cancel page callback: %q", value)
+		return
+	}
+
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	a.refreshCancelList(ctx, b, query, user.UserID, page)
+}
+
+// refreshCancelList edits the pressed message to show a page of the user's current bookings
+func (a *App) refreshCancelList(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, userID int, page int) {
+	bookings, err := a.store.GetUserBookings(ctx, userID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving your bookings.",
+		})
+		a.logger.Printf("Error getting bookings: %v", err)
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      callbackChatID(query),
+		MessageID:   callbackMessageID(query),
+		Text:        a.service.FormatUserBookings(bookings, page),
+		ParseMode:   models.ParseModeMarkdown,
+		ReplyMarkup: cancelKeyboard(bookings, page),
+	})
+}
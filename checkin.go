@@ -0,0 +1,108 @@
+// checkin.go
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// watchCheckIns asks organizers to check in once their meeting starts and
+// releases bookings nobody checked in to within the grace period. It runs
+// every minute until ctx is done.
+func (a *App) watchCheckIns(ctx context.Context, b *bot.Bot) {
+	grace := a.config.Booking.CheckInGraceDuration()
+	if grace <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := a.now()
+
+		released, err := a.store.ReleaseNoShows(ctx, now.Add(-grace))
+		if err != nil {
+			a.logger.Printf("Error releasing no-show bookings: %v", err)
+		}
+		for _, booking := range released {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: booking.TelegramID,
+				Text: fmt.Sprintf("❌ Nobody checked in, so %s %s-%s has been released for others to book.",
+					booking.RoomName, booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04")),
+			})
+			a.offerWaitlist(ctx, b, bookingSlot(booking))
+		}
+
+		started, err := a.store.GetUpcomingBookings(ctx, now.Add(-grace), now)
+		if err != nil {
+			a.logger.Printf("Error getting started bookings: %v", err)
+			continue
+		}
+		for _, booking := range started {
+			claimed, err := a.store.ClaimCheckInPrompt(ctx, booking.BookingID)
+			if err != nil {
+				a.logger.Printf("Error claiming check-in prompt for booking %d: %v", booking.BookingID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: booking.TelegramID,
+				Text: fmt.Sprintf("🟢 Your meeting in %s has started (%s-%s).\nPlease check in within %d minutes or the room will be released.",
+					booking.RoomName, booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"), a.config.Booking.CheckInGrace),
+				ReplyMarkup: &models.InlineKeyboardMarkup{
+					InlineKeyboard: [][]models.InlineKeyboardButton{
+						{{Text: "✅ Check in", CallbackData: callbackData("checkin", strconv.Itoa(booking.BookingID))}},
+					},
+				},
+			})
+		}
+	}
+}
+
+// checkinCallback records that the organizer is using the room
+func (a *App) checkinCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	bookingID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid checkin callback: %q", value)
+		return
+	}
+
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	// CheckIn only touches SUCCESS bookings owned by this user
+	text := "✅ Checked in. Enjoy your meeting!"
+	if err := a.store.CheckIn(ctx, bookingID, user.UserID); err != nil {
+		text = "Unable to check in. The booking may already have been released."
+		a.logger.Printf("Error checking in to booking %d: %v", bookingID, err)
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    callbackChatID(query),
+		MessageID: callbackMessageID(query),
+		Text:      text,
+	})
+}
@@ -1,50 +1,40 @@
-// db/postgres.go
+// db/PostgreSQL.go
 
 package db
 
 import (
+	"context"
 	"database/sql"
+	"telegrarmchatbot/internal/model"
+
 	_ "github.com/lib/pq"
 )
 
-func Connect() (*sql.DB, error) {
-	// Update with your actual credentials
-	connStr := "host=localhost port=5432 user=acia password=room_bot1234 dbname=room_booking_bot sslmode=disable"
-	
+// Connect opens the database at connStr, a postgres:// URL or key=value DSN
+func Connect(ctx context.Context, connStr string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, err
 	}
 	
 	// Test connection
-	if err = db.Ping(); err != nil {
+	if err = db.PingContext(ctx); err != nil {
 		return nil, err
 	}
 	
 	return db, nil
 }
 
-// SeedRooms inserts the 3 rooms if they don't exist
-func SeedRooms(db *sql.DB) error {
-	// First, add status column if it doesn't exist
-	_, err := db.Exec(`
-		ALTER TABLE rooms 
-		ADD COLUMN IF NOT EXISTS status VARCHAR(20) DEFAULT 'ACTIVE'
-	`)
-	if err != nil {
-		return err
-	}
-	
+// SeedRooms inserts the configured rooms if they don't exist
+func SeedRooms(ctx context.Context, db *sql.DB, rooms []model.Room) error {
 	// Insert rooms
-	rooms := []string{"Room A", "Room B", "Room C"}
-	
-	for _, roomName := range rooms {
+	for _, room := range rooms {
 		query := `
 		INSERT INTO rooms (room_name, capacity, status) 
-		VALUES ($1, 10, 'ACTIVE')
+		VALUES ($1, $2, 'ACTIVE')
 		ON CONFLICT (room_name) DO NOTHING`
 		
-		_, err := db.Exec(query, roomName)
+		_, err := db.ExecContext(ctx, query, room.RoomName, room.Capacity)
 		if err != nil {
 			return err
 		}
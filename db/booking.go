@@ -1,28 +1,48 @@
-// db/booking.go - NEW FILE
+// db/booking.go
 
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"telegrarmchatbot/internal/model"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// participantNames aggregates a booking's participants in the same query as the booking,
+// so listings need one round-trip instead of one per row. Used with
+// LEFT JOIN participants p and GROUP BY b.booking_id.
+const participantNames = `COALESCE(array_agg(p.name ORDER BY p.participant_id) FILTER (WHERE p.participant_id IS NOT NULL), '{}')`
+
+// ErrSlotTaken is returned when a booking overlaps an existing SUCCESS booking for the same room
+var ErrSlotTaken = errors.New("time slot already booked")
+
+// isSlotConflict reports whether err is the no_overlapping_bookings exclusion violation
+func isSlotConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23P01"
+}
+
 // GetBookingsByDate retrieves all bookings for a specific date
-func GetBookingsByDate(db *sql.DB, date time.Time) ([]model.Booking, error) {
+func GetBookingsByDate(ctx context.Context, db *sql.DB, date time.Time) ([]model.Booking, error) {
 	query := `
 	SELECT b.booking_id, b.room_id, b.user_id, b.topic, b.date, 
 	       b.start_time, b.end_time, b.status, b.create_at,
-	       r.room_name, u.username, u.fullname
+	       r.room_name, u.username, u.fullname, ` + participantNames + `
 	FROM bookings b
 	JOIN rooms r ON b.room_id = r.room_id
 	JOIN users u ON b.user_id = u.user_id
+	LEFT JOIN participants p ON p.booking_id = b.booking_id
 	WHERE b.date = $1 AND b.status = 'SUCCESS'
+	GROUP BY b.booking_id, r.room_id, u.user_id
 	ORDER BY r.room_name, b.start_time`
 
-	rows, err := db.Query(query, date)
+	rows, err := db.QueryContext(ctx, query, date)
 	if err != nil {
 		return nil, err
 	}
@@ -35,96 +55,151 @@ func GetBookingsByDate(db *sql.DB, date time.Time) ([]model.Booking, error) {
 			&booking.BookingID, &booking.RoomID, &booking.UserID, &booking.Topic,
 			&booking.Date, &booking.StartTime, &booking.EndTime, &booking.Status,
 			&booking.CreateAt, &booking.RoomName, &booking.Username, &booking.FullName,
+			pq.Array(&booking.Participants),
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		// Get participants for this booking
-		participants, _ := GetParticipantsByBookingID(db, booking.BookingID)
-		booking.Participants = participants
-
 		bookings = append(bookings, booking)
 	}
 
-	return bookings, nil
+	return bookings, rows.Err()
 }
 
-// CheckTimeConflict checks if a time slot is already booked
-func CheckTimeConflict(db *sql.DB, roomID int, date time.Time, startTime, endTime string) (bool, error) {
-	query := `
-	SELECT COUNT(*) FROM bookings 
-	WHERE room_id = $1 
-	AND date = $2 
-	AND status = 'SUCCESS'
-	AND (
-		(start_time < $4 AND end_time > $3) OR
-		(start_time >= $3 AND start_time < $4)
-	)`
-
-	var count int
-	err := db.QueryRow(query, roomID, date, startTime, endTime).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
-}
-
-// CreateBooking creates a new booking with participants
-func CreateBooking(db *sql.DB, booking *model.Booking, participants []string) error {
+// CreateBooking creates a new booking with participants.
+// It returns ErrSlotTaken if the room is already booked for an overlapping time.
+func CreateBooking(ctx context.Context, db *sql.DB, booking *model.Booking, participants []model.Participants) error {
 	// Start transaction
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if err := insertBooking(ctx, tx, booking, participants); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	return tx.Commit()
+}
+
+// insertBooking inserts a booking and its participants within tx,
+// returning ErrSlotTaken if it overlaps an existing booking.
+func insertBooking(ctx context.Context, tx *sql.Tx, booking *model.Booking, participants []model.Participants) error {
 	// Insert booking
 	query := `
-	INSERT INTO bookings (room_id, user_id, topic, date, start_time, end_time, status)
-	VALUES ($1, $2, $3, $4, $5, $6, 'SUCCESS')
+	INSERT INTO bookings (room_id, user_id, topic, date, start_time, end_time, status, series_id)
+	VALUES ($1, $2, $3, $4, $5, $6, 'SUCCESS', $7)
 	RETURNING booking_id, create_at`
 
-	err = tx.QueryRow(
-		query,
+	err := tx.QueryRowContext(
+		ctx, query,
 		booking.RoomID, booking.UserID, booking.Topic,
-		booking.Date, booking.StartTime, booking.EndTime,
+		booking.Date, booking.StartTime, booking.EndTime, booking.SeriesID,
 	).Scan(&booking.BookingID, &booking.CreateAt)
 
+	if isSlotConflict(err) {
+		return ErrSlotTaken
+	}
 	if err != nil {
 		return err
 	}
 
 	// Insert participants
 	if len(participants) > 0 {
-		participantQuery := `INSERT INTO participants (booking_id, name) VALUES ($1, $2)`
-		for _, name := range participants {
-			_, err = tx.Exec(participantQuery, booking.BookingID, name)
+		participantQuery := `INSERT INTO participants (booking_id, name, user_id) VALUES ($1, $2, $3)`
+		for _, participant := range participants {
+			_, err = tx.ExecContext(ctx, participantQuery, booking.BookingID, participant.Name, participant.UserID)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	// Commit transaction
+	return nil
+}
+
+// UpdateBookingTime moves one of the user's bookings to booking's room, date and times.
+// The booking is locked, checked for overlaps and updated in one transaction, so it
+// is either moved or left untouched. It returns ErrSlotTaken if the new time overlaps
+// another SUCCESS booking for the room.
+func UpdateBookingTime(ctx context.Context, db *sql.DB, booking *model.Booking) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the booking so a concurrent cancel or reschedule waits for us
+	var bookingID int
+	err = tx.QueryRowContext(ctx, `
+	SELECT booking_id FROM bookings
+	WHERE booking_id = $1 AND user_id = $2 AND status = 'SUCCESS'
+	FOR UPDATE`, booking.BookingID, booking.UserID).Scan(&bookingID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("booking not found or already cancelled")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The booking's current time doesn't count, so it can move into an overlapping range
+	var taken bool
+	err = tx.QueryRowContext(ctx, `
+	SELECT EXISTS (
+		SELECT 1 FROM bookings
+		WHERE room_id = $1 AND date = $2 AND status = 'SUCCESS' AND booking_id <> $3
+		AND start_time < $5::time AND end_time > $4::time
+	)`, booking.RoomID, booking.Date, booking.BookingID, booking.StartTime, booking.EndTime).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrSlotTaken
+	}
+
+	// no_overlapping_bookings still catches a booking inserted since the check.
+	// Check-in starts over at the new time.
+	_, err = tx.ExecContext(ctx, `
+	UPDATE bookings
+	SET room_id = $2, date = $3, start_time = $4, end_time = $5,
+	    checked_in_at = NULL, check_in_prompted_at = NULL
+	WHERE booking_id = $1`,
+		booking.BookingID, booking.RoomID, booking.Date, booking.StartTime, booking.EndTime)
+	if isSlotConflict(err) {
+		return ErrSlotTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	// Reminders sent for the old time must go out again for the new one
+	_, err = tx.ExecContext(ctx, `DELETE FROM booking_reminders WHERE booking_id = $1`, booking.BookingID)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // GetUserBookings retrieves all active bookings for a user
-func GetUserBookings(db *sql.DB, userID int) ([]model.Booking, error) {
+func GetUserBookings(ctx context.Context, db *sql.DB, userID int) ([]model.Booking, error) {
 	query := `
 	SELECT b.booking_id, b.room_id, b.user_id, b.topic, b.date, 
-	       b.start_time, b.end_time, b.status, b.create_at,
-	       r.room_name
+	       b.start_time, b.end_time, b.status, b.create_at, b.series_id,
+	       r.room_name, ` + participantNames + `
 	FROM bookings b
 	JOIN rooms r ON b.room_id = r.room_id
+	LEFT JOIN participants p ON p.booking_id = b.booking_id
 	WHERE b.user_id = $1 
 	AND b.status = 'SUCCESS'
 	AND b.date >= CURRENT_DATE
+	GROUP BY b.booking_id, r.room_id
 	ORDER BY b.date, b.start_time`
 
-	rows, err := db.Query(query, userID)
+	rows, err := db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -136,30 +211,26 @@ func GetUserBookings(db *sql.DB, userID int) ([]model.Booking, error) {
 		err := rows.Scan(
 			&booking.BookingID, &booking.RoomID, &booking.UserID, &booking.Topic,
 			&booking.Date, &booking.StartTime, &booking.EndTime, &booking.Status,
-			&booking.CreateAt, &booking.RoomName,
+			&booking.CreateAt, &booking.SeriesID, &booking.RoomName, pq.Array(&booking.Participants),
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		// Get participants
-		participants, _ := GetParticipantsByBookingID(db, booking.BookingID)
-		booking.Participants = participants
-
 		bookings = append(bookings, booking)
 	}
 
-	return bookings, nil
+	return bookings, rows.Err()
 }
 
 // CancelBooking marks a booking as cancelled
-func CancelBooking(db *sql.DB, bookingID int, userID int) error {
+func CancelBooking(ctx context.Context, db *sql.DB, bookingID int, userID int) error {
 	query := `
 	UPDATE bookings 
 	SET status = 'CANCELLED' 
 	WHERE booking_id = $1 AND user_id = $2 AND status = 'SUCCESS'`
 
-	result, err := db.Exec(query, bookingID, userID)
+	result, err := db.ExecContext(ctx, query, bookingID, userID)
 	if err != nil {
 		return err
 	}
@@ -177,10 +248,10 @@ func CancelBooking(db *sql.DB, bookingID int, userID int) error {
 }
 
 // GetParticipantsByBookingID retrieves all participants for a booking
-func GetParticipantsByBookingID(db *sql.DB, bookingID int) ([]string, error) {
+func GetParticipantsByBookingID(ctx context.Context, db *sql.DB, bookingID int) ([]string, error) {
 	query := `SELECT name FROM participants WHERE booking_id = $1`
 
-	rows, err := db.Query(query, bookingID)
+	rows, err := db.QueryContext(ctx, query, bookingID)
 	if err != nil {
 		return nil, err
 	}
@@ -195,34 +266,33 @@ func GetParticipantsByBookingID(db *sql.DB, bookingID int) ([]string, error) {
 		participants = append(participants, name)
 	}
 
-	return participants, nil
+	return participants, rows.Err()
 }
 
 // GetBookingByID retrieves a single booking with all details
-func GetBookingByID(db *sql.DB, bookingID int) (*model.Booking, error) {
+func GetBookingByID(ctx context.Context, db *sql.DB, bookingID int) (*model.Booking, error) {
 	query := `
 	SELECT b.booking_id, b.room_id, b.user_id, b.topic, b.date, 
-	       b.start_time, b.end_time, b.status, b.create_at,
-	       r.room_name, u.username, u.fullname
+	       b.start_time, b.end_time, b.status, b.create_at, b.series_id,
+	       r.room_name, u.username, u.fullname, ` + participantNames + `
 	FROM bookings b
 	JOIN rooms r ON b.room_id = r.room_id
 	JOIN users u ON b.user_id = u.user_id
-	WHERE b.booking_id = $1`
+	LEFT JOIN participants p ON p.booking_id = b.booking_id
+	WHERE b.booking_id = $1
+	GROUP BY b.booking_id, r.room_id, u.user_id`
 
 	var booking model.Booking
-	err := db.QueryRow(query, bookingID).Scan(
+	err := db.QueryRowContext(ctx, query, bookingID).Scan(
 		&booking.BookingID, &booking.RoomID, &booking.UserID, &booking.Topic,
 		&booking.Date, &booking.StartTime, &booking.EndTime, &booking.Status,
-		&booking.CreateAt, &booking.RoomName, &booking.Username, &booking.FullName,
+		&booking.CreateAt, &booking.SeriesID, &booking.RoomName, &booking.Username, &booking.FullName,
+		pq.Array(&booking.Participants),
 	)
 
 	if err != nil {
 		return nil, err
 	}
- 
-	// Get participants
-	participants, _ := GetParticipantsByBookingID(db, booking.BookingID)
-	booking.Participants = participants
 
 	return &booking, nil
 }
@@ -0,0 +1,173 @@
+// db/booking_test.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/internal/model"
+)
+
+// Benchmark fixture: benchRooms rooms booked for benchHours one-hour meetings,
+// each with benchParticipants participants, on a date nothing else uses
+const (
+	benchRooms        = 5
+	benchHours        = 8
+	benchParticipants = 4
+	benchTelegramID   = -15
+)
+
+var benchDate = time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// getBookingsByDatePerRow is GetBookingsByDate as it was before participants
+// were aggregated: one query for the bookings, then one per booking
+func getBookingsByDatePerRow(ctx context.Context, db *sql.DB, date time.Time) ([]model.Booking, error) {
+	query := `
+	SELECT b.booking_id, b.room_id, b.user_id, b.topic, b.date,
+	       b.start_time, b.end_time, b.status, b.create_at,
+	       r.room_name, u.username, u.fullname
+	FROM bookings b
+	JOIN rooms r ON b.room_id = r.room_id
+	JOIN users u ON b.user_id = u.user_id
+	WHERE b.date = $1 AND b.status = 'SUCCESS'
+	ORDER BY r.room_name, b.start_time`
+
+	rows, err := db.QueryContext(ctx, query, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []model.Booking
+	for rows.Next() {
+		var booking model.Booking
+		err := rows.Scan(
+			&booking.BookingID, &booking.RoomID, &booking.UserID, &booking.Topic,
+			&booking.Date, &booking.StartTime, &booking.EndTime, &booking.Status,
+			&booking.CreateAt, &booking.RoomName, &booking.Username, &booking.FullName,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		participants, err := GetParticipantsByBookingID(ctx, db, booking.BookingID)
+		if err != nil {
+			return nil, err
+		}
+		booking.Participants = participants
+
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// benchDatabase connects to DATABASE_URL and fills benchDate, skipping the
+// benchmark when no database is configured. The fixture is removed afterwards.
+func benchDatabase(b *testing.B) *sql.DB {
+	b.Helper()
+
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		b.Skip("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	db, err := Connect(ctx, url)
+	if err != nil {
+		b.Fatalf("connect: %v", err)
+	}
+	if err := MigrateUp(ctx, db); err != nil {
+		b.Fatalf("migrate: %v", err)
+	}
+
+	var roomNames []string
+	var rooms []model.Room
+	for i := 0; i < benchRooms; i++ {
+		name := fmt.Sprintf("Benchmark Room %d", i+1)
+		roomNames = append(roomNames, name)
+		rooms = append(rooms, model.Room{RoomName: name, Capacity: 10})
+	}
+
+	cleanup := func() {
+		db.ExecContext(ctx, `DELETE FROM users WHERE telegram_id = $1`, benchTelegramID)
+		for _, name := range roomNames {
+			db.ExecContext(ctx, `DELETE FROM rooms WHERE room_name = $1`, name)
+		}
+	}
+	cleanup()
+	b.Cleanup(func() {
+		cleanup()
+		db.Close()
+	})
+
+	if err := SeedRooms(ctx, db, rooms); err != nil {
+		b.Fatalf("seed rooms: %v", err)
+	}
+	user, err := CreateOrGetUser(ctx, db, benchTelegramID, "benchmark", "Benchmark User")
+	if err != nil {
+		b.Fatalf("create user: %v", err)
+	}
+
+	var participants []model.Participants
+	for i := 0; i < benchParticipants; i++ {
+		participants = append(participants, model.Participants{Name: fmt.Sprintf("Participant %d", i+1)})
+	}
+
+	for _, name := range roomNames {
+		room, err := GetRoomByName(ctx, db, name)
+		if err != nil {
+			b.Fatalf("get room: %v", err)
+		}
+		for hour := 0; hour < benchHours; hour++ {
+			start := benchDate.Add(time.Duration(9+hour) * time.Hour)
+			booking := model.Booking{
+				RoomID:    room.RoomID,
+				UserID:    user.UserID,
+				Topic:     "Benchmark",
+				Date:      benchDate,
+				StartTime: start,
+				EndTime:   start.Add(time.Hour),
+			}
+			if err := CreateBooking(ctx, db, &booking, participants); err != nil {
+				b.Fatalf("create booking: %v", err)
+			}
+		}
+	}
+
+	return db
+}
+
+// BenchmarkGetBookingsByDate compares one query per booking for the
+// participants with aggregating them in the bookings query
+func BenchmarkGetBookingsByDate(b *testing.B) {
+	db := benchDatabase(b)
+	ctx := context.Background()
+
+	benchmarks := []struct {
+		name  string
+		fetch func(context.Context, *sql.DB, time.Time) ([]model.Booking, error)
+	}{
+		{"PerRow", getBookingsByDatePerRow},
+		{"ArrayAgg", GetBookingsByDate},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bookings, err := bm.fetch(ctx, db, benchDate)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(bookings) != benchRooms*benchHours {
+					b.Fatalf("got %d bookings, want %d", len(bookings), benchRooms*benchHours)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// db/checkin.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"telegrarmchatbot/internal/model"
+	"time"
+)
+
+// ClaimCheckInPrompt records that the organizer of a started booking is being asked
+// to check in. It returns false if they already were, so each prompt goes out once.
+// Only prompted bookings can be released as no-shows.
+func ClaimCheckInPrompt(ctx context.Context, db *sql.DB, bookingID int) (bool, error) {
+	query := `
+	UPDATE bookings
+	SET check_in_prompted_at = CURRENT_TIMESTAMP
+	WHERE booking_id = $1 AND status = 'SUCCESS' AND check_in_prompted_at IS NULL`
+
+	result, err := db.ExecContext(ctx, query, bookingID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// CheckIn marks the user's booking as in use and records a CHECKED_IN event
+func CheckIn(ctx context.Context, db *sql.DB, bookingID int, userID int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE bookings
+	SET checked_in_at = CURRENT_TIMESTAMP
+	WHERE booking_id = $1 AND user_id = $2 AND status = 'SUCCESS' AND checked_in_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, query, bookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("booking not found, already checked in or released")
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO booking_events (booking_id, event) VALUES ($1, 'CHECKED_IN')`, bookingID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReleaseNoShows moves prompted bookings that started at or before startedBefore
+// and were not checked in to NO_SHOW, records a NO_SHOW event for each and returns them.
+// startedBefore is compared as local wall-clock time, like GetUpcomingBookings.
+func ReleaseNoShows(ctx context.Context, db *sql.DB, startedBefore time.Time) ([]model.Booking, error) {
+	query := `
+	WITH released AS (
+		UPDATE bookings b
+		SET status = 'NO_SHOW'
+		WHERE b.status = 'SUCCESS' AND b.checked_in_at IS NULL
+		AND b.check_in_prompted_at IS NOT NULL
+		AND b.date + b.start_time <= $1::timestamp
+		RETURNING b.*
+	), events AS (
+		INSERT INTO booking_events (booking_id, event)
+		SELECT booking_id, 'NO_SHOW' FROM released
+	)
+	SELECT b.booking_id, b.room_id, b.user_id, b.topic, b.date,
+	       b.start_time, b.end_time, b.status, b.create_at, b.series_id,
+	       r.room_name, u.username, u.fullname, u.telegram_id
+	FROM released b
+	JOIN rooms r ON b.room_id = r.room_id
+	JOIN users u ON b.user_id = u.user_id
+	ORDER BY b.date, b.start_time`
+
+	rows, err := db.QueryContext(ctx, query, startedBefore.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []model.Booking
+	for rows.Next() {
+		var booking model.Booking
+		err := rows.Scan(
+			&booking.BookingID, &booking.RoomID, &booking.UserID, &booking.Topic,
+			&booking.Date, &booking.StartTime, &booking.EndTime, &booking.Status,
+			&booking.CreateAt, &booking.SeriesID, &booking.RoomName, &booking.Username,
+			&booking.FullName, &booking.TelegramID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// GetRoomUtilization counts the check-ins and no-shows of bookings dated from
+// from up to, not including, to, per room with any. Rooms are sorted by name.
+func GetRoomUtilization(ctx context.Context, db *sql.DB, from, to time.Time) ([]model.RoomUtilization, error) {
+	query := `
+	SELECT r.room_id, r.room_name,
+	       COUNT(*) FILTER (WHERE e.event = 'CHECKED_IN'),
+	       COUNT(*) FILTER (WHERE e.event = 'NO_SHOW')
+	FROM booking_events e
+	JOIN bookings b ON e.booking_id = b.booking_id
+	JOIN rooms r ON b.room_id = r.room_id
+	WHERE b.date >= $1::date AND b.date < $2::date
+	GROUP BY r.room_id, r.room_name
+	ORDER BY r.room_name`
+
+	rows, err := db.QueryContext(ctx, query, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var utilization []model.RoomUtilization
+	for rows.Next() {
+		var room model.RoomUtilization
+		if err := rows.Scan(&room.RoomID, &room.RoomName, &room.CheckedIn, &room.NoShows); err != nil {
+			return nil, err
+		}
+		utilization = append(utilization, room)
+	}
+
+	return utilization, rows.Err()
+}
@@ -0,0 +1,135 @@
+// db/holds.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"telegrarmchatbot/internal/state"
+)
+
+// HoldSlot reserves startTime-endTime for telegramID until expiresAt, replacing
+// any previous hold of theirs. It returns false if another user's unexpired hold
+// overlaps. now and expiresAt are stored in UTC like booking_sessions.updated_at.
+func HoldSlot(ctx context.Context, db *sql.DB, roomID int, date time.Time, startTime, endTime string, telegramID int64, now, expiresAt time.Time) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Replicas holding in the same room take turns, so two can't both see it free
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('slot_holds'), $1)`, roomID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM slot_holds WHERE expires_at <= $1`, now.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	var taken bool
+	err = tx.QueryRowContext(ctx, `
+	SELECT EXISTS (
+		SELECT 1 FROM slot_holds
+		WHERE room_id = $1 AND date = $2::date AND telegram_id <> $3
+		AND start_time < $5::time AND end_time > $4::time
+	)`, roomID, date.Format("2006-01-02"), telegramID, startTime, endTime).Scan(&taken)
+	if err != nil {
+		return false, err
+	}
+	if taken {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO slot_holds (telegram_id, room_id, date, start_time, end_time, expires_at)
+	VALUES ($1, $2, $3::date, $4::time, $5::time, $6)
+	ON CONFLICT (telegram_id) DO UPDATE
+	SET room_id = EXCLUDED.room_id, date = EXCLUDED.date, start_time = EXCLUDED.start_time,
+	    end_time = EXCLUDED.end_time, expires_at = EXCLUDED.expires_at`,
+		telegramID, roomID, date.Format("2006-01-02"), startTime, endTime, expiresAt.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// GetSlotHolder returns the user holding any part of startTime-endTime, if the hold
+// has not expired at now
+func GetSlotHolder(ctx context.Context, db *sql.DB, roomID int, date time.Time, startTime, endTime string, now time.Time) (int64, bool, error) {
+	query := `
+	SELECT telegram_id FROM slot_holds
+	WHERE room_id = $1 AND date = $2::date
+	AND start_time < $4::time AND end_time > $3::time
+	AND expires_at > $5
+	LIMIT 1`
+
+	var telegramID int64
+	err := db.QueryRowContext(ctx, query, roomID, date.Format("2006-01-02"), startTime, endTime, now.UTC()).Scan(&telegramID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return telegramID, true, nil
+}
+
+// ExtendSlotHold moves the expiry of telegramID's unexpired hold to expiresAt
+func ExtendSlotHold(ctx context.Context, db *sql.DB, telegramID int64, now, expiresAt time.Time) error {
+	_, err := db.ExecContext(ctx, `UPDATE slot_holds SET expires_at = $3 WHERE telegram_id = $1 AND expires_at > $2`,
+		telegramID, now.UTC(), expiresAt.UTC())
+	return err
+}
+
+// ReleaseSlot drops the hold owned by telegramID
+func ReleaseSlot(ctx context.Context, db *sql.DB, telegramID int64) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM slot_holds WHERE telegram_id = $1`, telegramID)
+	return err
+}
+
+// PostgresHolds implements state.Holds on the slot_holds table, so every replica
+// sees and can release the same holds
+type PostgresHolds struct {
+	pg  *Postgres
+	now func() time.Time
+}
+
+var _ state.Holds = (*PostgresHolds)(nil)
+
+// NewPostgresHolds creates holds that read the time from now
+func NewPostgresHolds(database *sql.DB, timeout time.Duration, now func() time.Time) *PostgresHolds {
+	return &PostgresHolds{pg: NewPostgres(database, timeout), now: now}
+}
+
+func (p *PostgresHolds) Hold(ctx context.Context, roomID int, date time.Time, startTime, endTime string, userID int64, ttl time.Duration) (bool, error) {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	now := p.now()
+	return HoldSlot(ctx, p.pg.DB, roomID, date, startTime, endTime, userID, now, now.Add(ttl))
+}
+
+func (p *PostgresHolds) HeldBy(ctx context.Context, roomID int, date time.Time, startTime, endTime string) (int64, bool, error) {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	return GetSlotHolder(ctx, p.pg.DB, roomID, date, startTime, endTime, p.now())
+}
+
+func (p *PostgresHolds) Extend(ctx context.Context, userID int64, ttl time.Duration) error {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	now := p.now()
+	return ExtendSlotHold(ctx, p.pg.DB, userID, now, now.Add(ttl))
+}
+
+func (p *PostgresHolds) Release(ctx context.Context, userID int64) error {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	return ReleaseSlot(ctx, p.pg.DB, userID)
+}
@@ -0,0 +1,599 @@
+// db/memory.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"telegrarmchatbot/internal/model"
+	"time"
+)
+
+// bookingEvent is a row of booking_events
+type bookingEvent struct {
+	bookingID int
+	event     string // CHECKED_IN or NO_SHOW
+}
+
+// Memory is an in-process Store with the same behaviour as Postgres,
+// for tests and running the bot without a database.
+type Memory struct {
+	mu           sync.RWMutex
+	users        []model.User
+	rooms        []model.Room
+	bookings     []model.Booking
+	participants map[int][]model.Participants
+	series       []model.BookingSeries
+	reminders    map[[2]int]bool // booking ID and lead minutes
+	checkedIn    map[int]bool
+	prompted     map[int]bool // asked to check in
+	events       []bookingEvent
+	waitlist     []model.WaitlistEntry
+	now          func() time.Time
+}
+
+// NewMemory creates an empty store that reads the time from now
+func NewMemory(now func() time.Time) *Memory {
+	return &Memory{
+		now:          now,
+		participants: make(map[int][]model.Participants),
+		reminders:    make(map[[2]int]bool),
+		checkedIn:    make(map[int]bool),
+		prompted:     make(map[int]bool),
+	}
+}
+
+func (m *Memory) CreateOrGetUser(ctx context.Context, telegramID int64, username, fullName string) (*model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, user := range m.users {
+		if user.TelegramID == telegramID {
+			// Like Postgres, a changed username or name is kept current
+			if username != "" {
+				m.users[i].Username = username
+				m.users[i].FullName = fullName
+			}
+			user := m.users[i]
+			return &user, nil
+		}
+	}
+
+	user := model.User{
+		UserID:     len(m.users) + 1,
+		TelegramID: telegramID,
+		Username:   username,
+		FullName:   fullName,
+		CreateAt:   m.now(),
+	}
+	m.users = append(m.users, user)
+	return &user, nil
+}
+
+func (m *Memory) GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.TelegramID == telegramID {
+			return &user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *Memory) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Username != "" && strings.EqualFold(user.Username, strings.TrimLeft(username, "@")) {
+			return &user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *Memory) GetAllActiveRooms(ctx context.Context) ([]model.Room, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rooms []model.Room
+	for _, room := range m.rooms {
+		if room.Status == "ACTIVE" {
+			rooms = append(rooms, room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].RoomName < rooms[j].RoomName })
+	return rooms, nil
+}
+
+func (m *Memory) GetRoomByID(ctx context.Context, roomID int) (*model.Room, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, room := range m.rooms {
+		if room.RoomID == roomID {
+			return &room, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *Memory) GetRoomByName(ctx context.Context, roomName string) (*model.Room, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, room := range m.rooms {
+		if room.RoomName == roomName {
+			return &room, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *Memory) SeedRooms(ctx context.Context, rooms []model.Room) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, room := range rooms {
+		exists := false
+		for _, existing := range m.rooms {
+			if existing.RoomName == room.RoomName {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		room.RoomID = len(m.rooms) + 1
+		room.Status = "ACTIVE"
+		room.CreateAt = m.now()
+		m.rooms = append(m.rooms, room)
+	}
+	return nil
+}
+
+// withJoins fills the fields Postgres gets from joining rooms, users and participants
+func (m *Memory) withJoins(booking model.Booking) model.Booking {
+	for _, room := range m.rooms {
+		if room.RoomID == booking.RoomID {
+			booking.RoomName = room.RoomName
+		}
+	}
+	for _, user := range m.users {
+		if user.UserID == booking.UserID {
+			booking.Username = user.Username
+			booking.FullName = user.FullName
+			booking.TelegramID = user.TelegramID
+		}
+	}
+	booking.Participants = nil
+	for _, participant := range m.participants[booking.BookingID] {
+		booking.Participants = append(booking.Participants, participant.Name)
+	}
+	return booking
+}
+
+func sortBookings(bookings []model.Booking) {
+	sort.Slice(bookings, func(i, j int) bool {
+		a, b := bookings[i], bookings[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.RoomName != b.RoomName {
+			return a.RoomName < b.RoomName
+		}
+		return a.StartTime.Format("15:04") < b.StartTime.Format("15:04")
+	})
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+func (m *Memory) GetBookingsByDate(ctx context.Context, date time.Time) ([]model.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var bookings []model.Booking
+	for _, booking := range m.bookings {
+		if booking.Status == "SUCCESS" && sameDay(booking.Date, date) {
+			bookings = append(bookings, m.withJoins(booking))
+		}
+	}
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+// CreateBooking mirrors the no_overlapping_bookings constraint and returns ErrSlotTaken on overlap
+func (m *Memory) CreateBooking(ctx context.Context, booking *model.Booking, participants []model.Participants) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.insertBooking(booking, participants)
+}
+
+// insertBooking adds a booking; the caller holds m.mu
+func (m *Memory) insertBooking(booking *model.Booking, participants []model.Participants) error {
+	start, end := booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04")
+	for _, existing := range m.bookings {
+		if existing.Status == "SUCCESS" && existing.RoomID == booking.RoomID && sameDay(existing.Date, booking.Date) &&
+			existing.StartTime.Format("15:04") < end && existing.EndTime.Format("15:04") > start {
+			return ErrSlotTaken
+		}
+	}
+
+	booking.BookingID = len(m.bookings) + 1
+	booking.Status = "SUCCESS"
+	booking.CreateAt = m.now()
+	m.bookings = append(m.bookings, *booking)
+	m.participants[booking.BookingID] = append([]model.Participants(nil), participants...)
+	return nil
+}
+
+// CreateSeries books each occurrence that doesn't overlap, like the Postgres version
+func (m *Memory) CreateSeries(ctx context.Context, series *model.BookingSeries, occurrences []model.Booking, participants []model.Participants) ([]model.Booking, []model.Booking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Mirrors the booking_series CHECK
+	if series.UntilDate.IsZero() && series.Count <= 0 {
+		return nil, nil, fmt.Errorf("series has neither an end date nor an occurrence count")
+	}
+
+	seriesID := len(m.series) + 1
+
+	var booked, conflicts []model.Booking
+	for _, occurrence := range occurrences {
+		occurrence.SeriesID = &seriesID
+		if err := m.insertBooking(&occurrence, participants); err != nil {
+			conflicts = append(conflicts, occurrence)
+			continue
+		}
+		booked = append(booked, occurrence)
+	}
+
+	if len(booked) == 0 {
+		return nil, conflicts, ErrSlotTaken
+	}
+
+	series.SeriesID = seriesID
+	series.CreateAt = m.now()
+	m.series = append(m.series, *series)
+	return booked, conflicts, nil
+}
+
+func (m *Memory) GetUserBookings(ctx context.Context, userID int) ([]model.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var bookings []model.Booking
+	for _, booking := range m.bookings {
+		if booking.UserID == userID && booking.Status == "SUCCESS" && !booking.Date.Before(today) {
+			bookings = append(bookings, m.withJoins(booking))
+		}
+	}
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+func (m *Memory) CancelBooking(ctx context.Context, bookingID int, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.bookings {
+		booking := &m.bookings[i]
+		if booking.BookingID == bookingID && booking.UserID == userID && booking.Status == "SUCCESS" {
+			booking.Status = "CANCELLED"
+			return nil
+		}
+	}
+	return fmt.Errorf("booking not found or already cancelled")
+}
+
+// UpdateBookingTime applies the same overlap rule as CreateBooking, ignoring the booking itself
+func (m *Memory) UpdateBookingTime(ctx context.Context, booking *model.Booking) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var target *model.Booking
+	for i := range m.bookings {
+		existing := &m.bookings[i]
+		if existing.BookingID == booking.BookingID && existing.UserID == booking.UserID && existing.Status == "SUCCESS" {
+			target = existing
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("booking not found or already cancelled")
+	}
+
+	start, end := booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04")
+	for _, existing := range m.bookings {
+		if existing.BookingID != booking.BookingID && existing.Status == "SUCCESS" &&
+			existing.RoomID == booking.RoomID && sameDay(existing.Date, booking.Date) &&
+			existing.StartTime.Format("15:04") < end && existing.EndTime.Format("15:04") > start {
+			return ErrSlotTaken
+		}
+	}
+
+	target.RoomID = booking.RoomID
+	target.Date = booking.Date
+	target.StartTime = booking.StartTime
+	target.EndTime = booking.EndTime
+
+	for key := range m.reminders {
+		if key[0] == booking.BookingID {
+			delete(m.reminders, key)
+		}
+	}
+	delete(m.checkedIn, booking.BookingID)
+	delete(m.prompted, booking.BookingID)
+	return nil
+}
+
+func (m *Memory) GetBookingByID(ctx context.Context, bookingID int) (*model.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, booking := range m.bookings {
+		if booking.BookingID == bookingID {
+			booking = m.withJoins(booking)
+			return &booking, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *Memory) GetUpcomingBookings(ctx context.Context, from, to time.Time) ([]model.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var bookings []model.Booking
+	for _, booking := range m.bookings {
+		start := time.Date(booking.Date.Year(), booking.Date.Month(), booking.Date.Day(),
+			booking.StartTime.Hour(), booking.StartTime.Minute(), 0, 0, from.Location())
+		if booking.Status == "SUCCESS" && !start.Before(from) && start.Before(to) {
+			bookings = append(bookings, m.withJoins(booking))
+		}
+	}
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+func (m *Memory) GetParticipantTelegramIDs(ctx context.Context, bookingID int) ([]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var telegramIDs []int64
+	for _, participant := range m.participants[bookingID] {
+		if participant.UserID == nil {
+			continue
+		}
+		for _, user := range m.users {
+			if user.UserID == *participant.UserID && !slices.Contains(telegramIDs, user.TelegramID) {
+				telegramIDs = append(telegramIDs, user.TelegramID)
+			}
+		}
+	}
+	return telegramIDs, nil
+}
+
+func (m *Memory) ClaimReminder(ctx context.Context, bookingID int, leadMinutes int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]int{bookingID, leadMinutes}
+	if m.reminders[key] {
+		return false, nil
+	}
+	m.reminders[key] = true
+	return true, nil
+}
+
+func (m *Memory) ClaimCheckInPrompt(ctx context.Context, bookingID int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, booking := range m.bookings {
+		if booking.BookingID == bookingID && booking.Status == "SUCCESS" && !m.prompted[bookingID] {
+			m.prompted[bookingID] = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *Memory) CheckIn(ctx context.Context, bookingID int, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, booking := range m.bookings {
+		if booking.BookingID == bookingID && booking.UserID == userID && booking.Status == "SUCCESS" && !m.checkedIn[bookingID] {
+			m.checkedIn[bookingID] = true
+			m.events = append(m.events, bookingEvent{bookingID: bookingID, event: "CHECKED_IN"})
+			return nil
+		}
+	}
+	return fmt.Errorf("booking not found, already checked in or released")
+}
+
+func (m *Memory) ReleaseNoShows(ctx context.Context, startedBefore time.Time) ([]model.Booking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var released []model.Booking
+	for i := range m.bookings {
+		booking := &m.bookings[i]
+		start := time.Date(booking.Date.Year(), booking.Date.Month(), booking.Date.Day(),
+			booking.StartTime.Hour(), booking.StartTime.Minute(), 0, 0, startedBefore.Location())
+		if booking.Status == "SUCCESS" && !m.checkedIn[booking.BookingID] && !start.After(startedBefore) &&
+			m.prompted[booking.BookingID] {
+			booking.Status = "NO_SHOW"
+			m.events = append(m.events, bookingEvent{bookingID: booking.BookingID, event: "NO_SHOW"})
+			released = append(released, m.withJoins(*booking))
+		}
+	}
+	sortBookings(released)
+	return released, nil
+}
+
+func (m *Memory) GetRoomUtilization(ctx context.Context, from, to time.Time) ([]model.RoomUtilization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	rooms := make(map[int]*model.RoomUtilization)
+	for _, event := range m.events {
+		for _, booking := range m.bookings {
+			if booking.BookingID != event.bookingID {
+				continue
+			}
+			day := time.Date(booking.Date.Year(), booking.Date.Month(), booking.Date.Day(), 0, 0, 0, 0, time.UTC)
+			if day.Before(first) || !day.Before(last) {
+				continue
+			}
+			room, ok := rooms[booking.RoomID]
+			if !ok {
+				room = &model.RoomUtilization{RoomID: booking.RoomID, RoomName: m.withJoins(booking).RoomName}
+				rooms[booking.RoomID] = room
+			}
+			if event.event == "CHECKED_IN" {
+				room.CheckedIn++
+			} else {
+				room.NoShows++
+			}
+		}
+	}
+
+	var utilization []model.RoomUtilization
+	for _, room := range rooms {
+		utilization = append(utilization, *room)
+	}
+	sort.Slice(utilization, func(i, j int) bool { return utilization[i].RoomName < utilization[j].RoomName })
+	return utilization, nil
+}
+
+// sameSlot reports whether two waitlist entries wait for the same room, date and time range
+func sameSlot(a, b model.WaitlistEntry) bool {
+	return a.RoomID == b.RoomID && sameDay(a.Date, b.Date) &&
+		a.StartTime.Format("15:04") == b.StartTime.Format("15:04") && a.EndTime.Format("15:04") == b.EndTime.Format("15:04")
+}
+
+func activeWaitlistStatus(status string) bool {
+	return status == "WAITING" || status == "OFFERED"
+}
+
+// waitlistJoins fills the fields Postgres gets from joining rooms and users
+func (m *Memory) waitlistJoins(entry model.WaitlistEntry) model.WaitlistEntry {
+	for _, room := range m.rooms {
+		if room.RoomID == entry.RoomID {
+			entry.RoomName = room.RoomName
+		}
+	}
+	for _, user := range m.users {
+		if user.UserID == entry.UserID {
+			entry.TelegramID = user.TelegramID
+		}
+	}
+	return entry
+}
+
+func (m *Memory) JoinWaitlist(ctx context.Context, entry *model.WaitlistEntry) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	position := 0
+	for _, existing := range m.waitlist {
+		if !sameSlot(existing, *entry) || !activeWaitlistStatus(existing.Status) {
+			continue
+		}
+		position++
+		if existing.UserID == entry.UserID {
+			return position, nil
+		}
+	}
+
+	entry.WaitlistID = len(m.waitlist) + 1
+	entry.Status = "WAITING"
+	entry.CreateAt = m.now()
+	m.waitlist = append(m.waitlist, *entry)
+	return position + 1, nil
+}
+
+func (m *Memory) OfferNextWaitlist(ctx context.Context, slot model.WaitlistEntry, expiresAt time.Time) (*model.WaitlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.waitlist {
+		if sameSlot(existing, slot) && existing.Status == "OFFERED" {
+			return nil, nil
+		}
+	}
+
+	for i := range m.waitlist {
+		entry := &m.waitlist[i]
+		if sameSlot(*entry, slot) && entry.Status == "WAITING" {
+			entry.Status = "OFFERED"
+			entry.OfferExpiresAt = expiresAt
+			offered := m.waitlistJoins(*entry)
+			return &offered, nil
+		}
+	}
+	return nil, nil
+}
+
+// answerOffer moves the open offer waitlistID made to telegramID to status; the caller holds m.mu
+func (m *Memory) answerOffer(waitlistID int, telegramID int64, status string, now time.Time) (*model.WaitlistEntry, error) {
+	for i := range m.waitlist {
+		entry := &m.waitlist[i]
+		if entry.WaitlistID != waitlistID || entry.Status != "OFFERED" || m.waitlistJoins(*entry).TelegramID != telegramID {
+			continue
+		}
+		if !now.IsZero() && !entry.OfferExpiresAt.After(now) {
+			break
+		}
+		entry.Status = status
+		answered := m.waitlistJoins(*entry)
+		return &answered, nil
+	}
+	return nil, ErrNoOffer
+}
+
+func (m *Memory) ClaimWaitlistOffer(ctx context.Context, waitlistID int, telegramID int64, now time.Time) (*model.WaitlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.answerOffer(waitlistID, telegramID, "CLAIMED", now)
+}
+
+func (m *Memory) PassWaitlistOffer(ctx context.Context, waitlistID int, telegramID int64) (*model.WaitlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.answerOffer(waitlistID, telegramID, "PASSED", time.Time{})
+}
+
+func (m *Memory) ExpireWaitlistOffers(ctx context.Context, now time.Time) ([]model.WaitlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expired []model.WaitlistEntry
+	for i := range m.waitlist {
+		entry := &m.waitlist[i]
+		if entry.Status == "OFFERED" && !entry.OfferExpiresAt.After(now) {
+			entry.Status = "EXPIRED"
+			expired = append(expired, m.waitlistJoins(*entry))
+		}
+	}
+	return expired, nil
+}
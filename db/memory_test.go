@@ -0,0 +1,154 @@
+// db/memory_test.go
+
+package db
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/internal/model"
+)
+
+func TestMemoryGetUserBookings(t *testing.T) {
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		date time.Time
+		want int
+	}{
+		{"yesterday", today.AddDate(0, 0, -1), 0},
+		{"today", today, 1},
+		{"next year", today.AddDate(1, 0, 0), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			// The store's clock, not the machine's, decides what is upcoming
+			m := NewMemory(func() time.Time { return today.Add(15 * time.Hour) })
+			user, err := m.CreateOrGetUser(ctx, 1, "organizer", "Organizer")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			booking := model.Booking{
+				RoomID:    1,
+				UserID:    user.UserID,
+				Date:      tt.date,
+				StartTime: tt.date.Add(9 * time.Hour),
+				EndTime:   tt.date.Add(10 * time.Hour),
+			}
+			if err := m.CreateBooking(ctx, &booking, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			bookings, err := m.GetUserBookings(ctx, user.UserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bookings) != tt.want {
+				t.Errorf("got %d bookings, want %d", len(bookings), tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryCreateSeries(t *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	occurrence := model.Booking{RoomID: 1, UserID: 1, Date: day, StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour)}
+
+	tests := []struct {
+		name    string
+		series  model.BookingSeries
+		wantErr bool
+	}{
+		{"count", model.BookingSeries{Frequency: model.FrequencyDaily, Count: 2}, false},
+		{"until date", model.BookingSeries{Frequency: model.FrequencyDaily, UntilDate: day.AddDate(0, 0, 1)}, false},
+		{"neither count nor until date", model.BookingSeries{Frequency: model.FrequencyDaily}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory(func() time.Time { return day })
+			series := tt.series
+			_, _, err := m.CreateSeries(context.Background(), &series, []model.Booking{occurrence}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateSeries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryGetUserByUsername(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(time.Now)
+	if _, err := m.CreateOrGetUser(ctx, 1, "", "Alice Smith"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.GetUserByUsername(ctx, "@alice"); err == nil {
+		t.Fatal("found a user before they had a username")
+	}
+
+	// Writing to the bot later fills in the username
+	if _, err := m.CreateOrGetUser(ctx, 1, "alice", "Alice Smith"); err != nil {
+		t.Fatal(err)
+	}
+	user, err := m.GetUserByUsername(ctx, "@Alice")
+	if err != nil {
+		t.Fatalf("GetUserByUsername() error = %v", err)
+	}
+	if user.TelegramID != 1 {
+		t.Errorf("GetUserByUsername() = telegram ID %d, want 1", user.TelegramID)
+	}
+}
+
+func TestMemoryGetRoomUtilization(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	m := NewMemory(func() time.Time { return day })
+	if err := m.SeedRooms(ctx, []model.Room{{RoomName: "Room A"}, {RoomName: "Room B"}}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := m.CreateOrGetUser(ctx, 1, "organizer", "Organizer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Room A: checked in at 9, a no-show at 10 and one a week later; Room B: untouched
+	book := func(roomID int, date time.Time, hour int) int {
+		booking := model.Booking{RoomID: roomID, UserID: user.UserID, Date: date,
+			StartTime: date.Add(time.Duration(hour) * time.Hour), EndTime: date.Add(time.Duration(hour+1) * time.Hour)}
+		if err := m.CreateBooking(ctx, &booking, nil); err != nil {
+			t.Fatal(err)
+		}
+		return booking.BookingID
+	}
+	checkedIn := book(1, day, 9)
+	noShow := book(1, day, 10)
+	nextWeek := book(1, day.AddDate(0, 0, 7), 10)
+	book(2, day, 9)
+
+	if err := m.CheckIn(ctx, checkedIn, user.UserID); err != nil {
+		t.Fatal(err)
+	}
+	for _, bookingID := range []int{noShow, nextWeek} {
+		if _, err := m.ClaimCheckInPrompt(ctx, bookingID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.ReleaseNoShows(ctx, day.AddDate(0, 0, 8)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.GetRoomUtilization(ctx, day, day.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.RoomUtilization{{RoomID: 1, RoomName: "Room A", CheckedIn: 1, NoShows: 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetRoomUtilization() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS participants;
+DROP TABLE IF EXISTS bookings;
+DROP TABLE IF EXISTS rooms;
+DROP TABLE IF EXISTS users;
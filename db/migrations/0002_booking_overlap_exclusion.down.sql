@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS no_overlapping_bookings;
+
+ALTER TABLE bookings ADD CONSTRAINT unique_booking UNIQUE (room_id, date, start_time, end_time);
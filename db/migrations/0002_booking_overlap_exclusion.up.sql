@@ -0,0 +1,40 @@
+-- Overlapping SUCCESS bookings for the same room are rejected by the database itself,
+-- so concurrent inserts cannot both win. This replaces the old exact-match unique_booking.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS unique_booking;
+
+DO $$
+DECLARE
+    booking RECORD;
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'no_overlapping_bookings') THEN
+        -- The old constraint only caught exact duplicates, so overlaps may already exist.
+        -- The earliest booking keeps the room; every later one that overlaps a kept
+        -- booking is cancelled, with a WARNING in the server log, since the constraint
+        -- could not be added otherwise.
+        FOR booking IN
+            SELECT booking_id, room_id, date, start_time, end_time FROM bookings
+            WHERE status = 'SUCCESS'
+            ORDER BY booking_id
+        LOOP
+            IF EXISTS (
+                SELECT 1 FROM bookings kept
+                WHERE kept.status = 'SUCCESS' AND kept.room_id = booking.room_id
+                AND kept.booking_id < booking.booking_id
+                AND tsrange(kept.date + kept.start_time, kept.date + kept.end_time)
+                    && tsrange(booking.date + booking.start_time, booking.date + booking.end_time)
+            ) THEN
+                UPDATE bookings SET status = 'CANCELLED' WHERE booking_id = booking.booking_id;
+                RAISE WARNING 'cancelled booking % (room %, % %-%): it overlaps an earlier booking',
+                    booking.booking_id, booking.room_id, booking.date, booking.start_time, booking.end_time;
+            END IF;
+        END LOOP;
+
+        ALTER TABLE bookings ADD CONSTRAINT no_overlapping_bookings EXCLUDE USING gist (
+            room_id WITH =,
+            tsrange(date + start_time, date + end_time) WITH &&
+        ) WHERE (status = 'SUCCESS');
+    END IF;
+END
+$$;
@@ -0,0 +1,19 @@
+-- In-progress /book conversations, so they survive restarts and are shared between replicas
+CREATE TABLE IF NOT EXISTS booking_sessions (
+    telegram_id BIGINT PRIMARY KEY,
+    data JSONB NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Slots reserved by users in the middle of booking, shared between replicas
+-- when sessions use the postgres backend. At most one hold per user.
+CREATE TABLE IF NOT EXISTS slot_holds (
+    telegram_id BIGINT PRIMARY KEY,
+    room_id INT NOT NULL REFERENCES rooms(room_id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    expires_at TIMESTAMP NOT NULL -- UTC
+);
+
+CREATE INDEX IF NOT EXISTS slot_holds_room_date ON slot_holds (room_id, date);
@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS booking_series;
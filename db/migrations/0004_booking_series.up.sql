@@ -0,0 +1,19 @@
+-- Recurring meetings. Each occurrence is still an ordinary row in bookings,
+-- so it can be cancelled or rescheduled on its own.
+CREATE TABLE IF NOT EXISTS booking_series (
+    series_id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(user_id) ON DELETE CASCADE,
+    room_id INT REFERENCES rooms(room_id) ON DELETE CASCADE,
+    topic VARCHAR(200),
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('DAILY', 'WEEKLY', 'MONTHLY')),
+    weekdays INT[] NOT NULL DEFAULT '{}',
+    start_date DATE NOT NULL,
+    until_date DATE,
+    occurrence_count INT,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (until_date IS NOT NULL OR occurrence_count IS NOT NULL)
+);
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS series_id INT REFERENCES booking_series(series_id) ON DELETE SET NULL;
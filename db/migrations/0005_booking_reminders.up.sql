@@ -0,0 +1,7 @@
+-- Reminders already sent, so a restart or a second replica doesn't send them again
+CREATE TABLE IF NOT EXISTS booking_reminders (
+    booking_id INT REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    lead_minutes INT NOT NULL,
+    sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (booking_id, lead_minutes)
+);
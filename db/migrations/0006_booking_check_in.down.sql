@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS booking_events;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS check_in_prompted_at;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS checked_in_at;
@@ -0,0 +1,16 @@
+-- Organizers check in once a meeting starts. Bookings nobody checks in to move to
+-- status NO_SHOW, which frees the slot because no_overlapping_bookings only covers SUCCESS.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
+
+-- When the organizer was asked to check in. Only prompted bookings can become no-shows.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS check_in_prompted_at TIMESTAMP;
+
+-- Check-ins and no-shows, for utilization reporting
+CREATE TABLE IF NOT EXISTS booking_events (
+    event_id SERIAL PRIMARY KEY,
+    booking_id INT REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    event VARCHAR(20) NOT NULL CHECK (event IN ('CHECKED_IN', 'NO_SHOW')),
+    create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS booking_events_booking_id ON booking_events (booking_id);
@@ -0,0 +1,19 @@
+-- Users waiting for a booked time range to free up. When it does, the first WAITING
+-- entry is OFFERED the range until offer_expires_at, then CLAIMED, PASSED or EXPIRED.
+CREATE TABLE IF NOT EXISTS waitlist (
+    waitlist_id SERIAL PRIMARY KEY,
+    room_id INT REFERENCES rooms(room_id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(user_id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'WAITING'
+        CHECK (status IN ('WAITING', 'OFFERED', 'CLAIMED', 'PASSED', 'EXPIRED')),
+    offer_expires_at TIMESTAMP,
+    create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user waits for the same range at most once
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_active_entry
+    ON waitlist (room_id, date, start_time, end_time, user_id)
+    WHERE status IN ('WAITING', 'OFFERED');
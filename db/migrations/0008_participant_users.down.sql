@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS participants_user_id;
+
+ALTER TABLE participants DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,13 @@
+-- Participants who are Telegram users, so they can be messaged about the booking.
+-- Plain names typed by the organizer keep user_id NULL.
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS user_id INT REFERENCES users(user_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS participants_user_id ON participants (user_id);
+
+-- Link existing participants entered as @username of someone who has used the bot
+UPDATE participants p
+SET user_id = u.user_id
+FROM users u
+WHERE p.user_id IS NULL
+  AND u.username <> ''
+  AND lower(u.username) = lower(ltrim(p.name, '@'));
@@ -0,0 +1,145 @@
+// db/parity_test.go
+
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/internal/model"
+)
+
+// Parity fixture: a room, a user and a date nothing else uses
+const (
+	parityRoom       = "Parity Room"
+	parityTelegramID = -16
+)
+
+var parityDate = time.Date(2099, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// parityStores returns the stores that must behave alike: Memory always, and
+// Postgres when DATABASE_URL is set. The Postgres fixture is removed afterwards.
+func parityStores(t *testing.T) map[string]Store {
+	t.Helper()
+	stores := map[string]Store{
+		"Memory": NewMemory(func() time.Time { return parityDate.AddDate(0, 0, -1) }),
+	}
+
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Log("DATABASE_URL is not set; checking Memory only")
+		return stores
+	}
+
+	ctx := context.Background()
+	db, err := Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := MigrateUp(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	cleanup := func() {
+		db.ExecContext(ctx, `DELETE FROM users WHERE telegram_id = $1`, parityTelegramID)
+		db.ExecContext(ctx, `DELETE FROM rooms WHERE room_name = $1`, parityRoom)
+	}
+	cleanup()
+	t.Cleanup(func() {
+		cleanup()
+		db.Close()
+	})
+
+	stores["Postgres"] = NewPostgres(db, 5*time.Second)
+	return stores
+}
+
+// spans lists the bookings in room as "15:04-15:04"
+func spans(bookings []model.Booking, roomID int) []string {
+	var got []string
+	for _, booking := range bookings {
+		if booking.RoomID == roomID {
+			got = append(got, booking.StartTime.Format("15:04")+"-"+booking.EndTime.Format("15:04"))
+		}
+	}
+	return got
+}
+
+func TestStoreParity(t *testing.T) {
+	for name, store := range parityStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := store.SeedRooms(ctx, []model.Room{{RoomName: parityRoom, Capacity: 4}}); err != nil {
+				t.Fatal(err)
+			}
+			room, err := store.GetRoomByName(ctx, parityRoom)
+			if err != nil {
+				t.Fatal(err)
+			}
+			user, err := store.CreateOrGetUser(ctx, parityTelegramID, "parity", "Parity User")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			book := func(start, end int, participants ...string) (*model.Booking, error) {
+				var list []model.Participants
+				for _, participant := range participants {
+					list = append(list, model.Participants{Name: participant})
+				}
+				booking := &model.Booking{
+					RoomID:    room.RoomID,
+					UserID:    user.UserID,
+					Topic:     "Parity",
+					Date:      parityDate,
+					StartTime: parityDate.Add(time.Duration(start) * time.Hour),
+					EndTime:   parityDate.Add(time.Duration(end) * time.Hour),
+				}
+				return booking, store.CreateBooking(ctx, booking, list)
+			}
+
+			first, err := book(9, 11, "Bob")
+			if err != nil {
+				t.Fatalf("first booking: %v", err)
+			}
+			if _, err := book(10, 12); !errors.Is(err, ErrSlotTaken) {
+				t.Errorf("overlapping booking error = %v, want ErrSlotTaken", err)
+			}
+			if _, err := book(11, 12); err != nil {
+				t.Errorf("adjacent booking: %v", err)
+			}
+
+			bookings, err := store.GetBookingsByDate(ctx, parityDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := spans(bookings, room.RoomID); !slices.Equal(got, []string{"09:00-11:00", "11:00-12:00"}) {
+				t.Errorf("bookings = %v, want 09:00-11:00 and 11:00-12:00", got)
+			}
+			for _, booking := range bookings {
+				if booking.BookingID == first.BookingID && !slices.Equal(booking.Participants, []string{"Bob"}) {
+					t.Errorf("participants = %v, want [Bob]", booking.Participants)
+				}
+			}
+
+			// Cancelling frees the range for a new booking
+			if err := store.CancelBooking(ctx, first.BookingID, user.UserID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := book(10, 11); err != nil {
+				t.Errorf("booking a cancelled range: %v", err)
+			}
+
+			upcoming, err := store.GetUserBookings(ctx, user.UserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := spans(upcoming, room.RoomID); !slices.Equal(got, []string{"10:00-11:00", "11:00-12:00"}) {
+				t.Errorf("user bookings = %v, want 10:00-11:00 and 11:00-12:00", got)
+			}
+		})
+	}
+}
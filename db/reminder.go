@@ -0,0 +1,103 @@
+// db/reminder.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"telegrarmchatbot/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetUpcomingBookings retrieves SUCCESS bookings starting in [from, to), with the
+// organizer's telegram ID. Both bounds are compared as local wall-clock times,
+// the same way bookings store them.
+func GetUpcomingBookings(ctx context.Context, db *sql.DB, from, to time.Time) ([]model.Booking, error) {
+	query := `
+	SELECT b.booking_id, b.room_id, b.user_id, b.topic, b.date, 
+	       b.start_time, b.end_time, b.status, b.create_at, b.series_id,
+	       r.room_name, u.username, u.fullname, u.telegram_id, ` + participantNames + `
+	FROM bookings b
+	JOIN rooms r ON b.room_id = r.room_id
+	JOIN users u ON b.user_id = u.user_id
+	LEFT JOIN participants p ON p.booking_id = b.booking_id
+	WHERE b.status = 'SUCCESS'
+	AND b.date + b.start_time >= $1::timestamp
+	AND b.date + b.start_time < $2::timestamp
+	GROUP BY b.booking_id, r.room_id, u.user_id
+	ORDER BY b.date, b.start_time`
+
+	rows, err := db.QueryContext(ctx, query, from.Format("2006-01-02 15:04:05"), to.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []model.Booking
+	for rows.Next() {
+		var booking model.Booking
+		err := rows.Scan(
+			&booking.BookingID, &booking.RoomID, &booking.UserID, &booking.Topic,
+			&booking.Date, &booking.StartTime, &booking.EndTime, &booking.Status,
+			&booking.CreateAt, &booking.SeriesID, &booking.RoomName, &booking.Username,
+			&booking.FullName, &booking.TelegramID, pq.Array(&booking.Participants),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// GetParticipantTelegramIDs returns the telegram IDs of a booking's participants
+// who are Telegram users
+func GetParticipantTelegramIDs(ctx context.Context, db *sql.DB, bookingID int) ([]int64, error) {
+	query := `
+	SELECT DISTINCT u.telegram_id
+	FROM participants p
+	JOIN users u ON u.user_id = p.user_id
+	WHERE p.booking_id = $1`
+
+	rows, err := db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var telegramIDs []int64
+	for rows.Next() {
+		var telegramID int64
+		if err := rows.Scan(&telegramID); err != nil {
+			return nil, err
+		}
+		telegramIDs = append(telegramIDs, telegramID)
+	}
+
+	return telegramIDs, rows.Err()
+}
+
+// ClaimReminder records that the reminder leadMinutes before the booking is being sent.
+// It returns false if it was already claimed, so each reminder goes out once.
+func ClaimReminder(ctx context.Context, db *sql.DB, bookingID int, leadMinutes int) (bool, error) {
+	query := `
+	INSERT INTO booking_reminders (booking_id, lead_minutes)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING`
+
+	result, err := db.ExecContext(ctx, query, bookingID, leadMinutes)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
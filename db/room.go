@@ -3,15 +3,16 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"telegrarmchatbot/internal/model"
 )
 
-func GetAllActiveRooms(db *sql.DB) ([]model.Room, error) {
+func GetAllActiveRooms(ctx context.Context, db *sql.DB) ([]model.Room, error) {
 	query := `SELECT room_id, room_name, capacity, COALESCE(status, 'ACTIVE'), create_at 
 	          FROM rooms WHERE COALESCE(status, 'ACTIVE') = 'ACTIVE' ORDER BY room_name`
 	
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -30,12 +31,12 @@ func GetAllActiveRooms(db *sql.DB) ([]model.Room, error) {
 	return rooms, nil
 }
 
-func GetRoomByID(db *sql.DB, roomID int) (*model.Room, error) {
+func GetRoomByID(ctx context.Context, db *sql.DB, roomID int) (*model.Room, error) {
 	var room model.Room
 	query := `SELECT room_id, room_name, capacity, COALESCE(status, 'ACTIVE'), create_at 
 	          FROM rooms WHERE room_id = $1`
 	
-	err := db.QueryRow(query, roomID).Scan(
+	err := db.QueryRowContext(ctx, query, roomID).Scan(
 		&room.RoomID, &room.RoomName, &room.Capacity, &room.Status, &room.CreateAt,
 	)
 	
@@ -46,12 +47,12 @@ func GetRoomByID(db *sql.DB, roomID int) (*model.Room, error) {
 	return &room, nil
 }
 
-func GetRoomByName(db *sql.DB, roomName string) (*model.Room, error) {
+func GetRoomByName(ctx context.Context, db *sql.DB, roomName string) (*model.Room, error) {
 	var room model.Room
 	query := `SELECT room_id, room_name, capacity, COALESCE(status, 'ACTIVE'), create_at 
 	          FROM rooms WHERE room_name = $1`
 	
-	err := db.QueryRow(query, roomName).Scan(
+	err := db.QueryRowContext(ctx, query, roomName).Scan(
 		&room.RoomID, &room.RoomName, &room.Capacity, &room.Status, &room.CreateAt,
 	)
 	
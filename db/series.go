@@ -0,0 +1,87 @@
+// db/series.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"telegrarmchatbot/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// CreateSeries stores a recurring booking and books each of its occurrences with
+// the given participants. Occurrences that overlap an existing booking are left
+// out and returned as conflicts; the rest are returned as booked, with BookingID
+// and SeriesID set. If no occurrence can be booked nothing is stored and
+// ErrSlotTaken is returned.
+func CreateSeries(ctx context.Context, db *sql.DB, series *model.BookingSeries, occurrences []model.Booking, participants []model.Participants) (booked, conflicts []model.Booking, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	weekdays := make([]int64, len(series.Weekdays))
+	for i, weekday := range series.Weekdays {
+		weekdays[i] = int64(weekday)
+	}
+
+	// A zero UntilDate or Count is stored as NULL
+	var untilDate, count any
+	if !series.UntilDate.IsZero() {
+		untilDate = series.UntilDate
+	}
+	if series.Count > 0 {
+		count = series.Count
+	}
+
+	query := `
+	INSERT INTO booking_series (user_id, room_id, topic, frequency, weekdays,
+	                            start_date, until_date, occurrence_count, start_time, end_time)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING series_id, create_at`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		series.UserID, series.RoomID, series.Topic, series.Frequency, pq.Array(weekdays),
+		series.StartDate, untilDate, count, series.StartTime, series.EndTime,
+	).Scan(&series.SeriesID, &series.CreateAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, occurrence := range occurrences {
+		occurrence.SeriesID = &series.SeriesID
+
+		// A conflict aborts the statement, so each occurrence gets its own savepoint
+		// to roll back to without losing the ones already booked
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT occurrence`); err != nil {
+			return nil, nil, err
+		}
+
+		err := insertBooking(ctx, tx, &occurrence, participants)
+		if errors.Is(err, ErrSlotTaken) {
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT occurrence`); err != nil {
+				return nil, nil, err
+			}
+			conflicts = append(conflicts, occurrence)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT occurrence`); err != nil {
+			return nil, nil, err
+		}
+		booked = append(booked, occurrence)
+	}
+
+	if len(booked) == 0 {
+		return nil, conflicts, ErrSlotTaken
+	}
+
+	return booked, conflicts, tx.Commit()
+}
@@ -0,0 +1,126 @@
+// db/session.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"telegrarmchatbot/internal/state"
+	"time"
+)
+
+// GetSession loads a user's booking session, or nil if they have none
+func GetSession(ctx context.Context, db *sql.DB, telegramID int64) (*state.BookingSession, error) {
+	var data []byte
+	query := `SELECT data FROM booking_sessions WHERE telegram_id = $1`
+
+	err := db.QueryRowContext(ctx, query, telegramID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session state.BookingSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// SaveSession inserts or replaces a user's booking session
+func SaveSession(ctx context.Context, db *sql.DB, telegramID int64, session *state.BookingSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO booking_sessions (telegram_id, data, updated_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (telegram_id) DO UPDATE SET data = EXCLUDED.data, updated_at = EXCLUDED.updated_at`
+
+	// updated_at mirrors LastActivity in UTC so RemoveExpiredSessions can filter on the column
+	_, err = db.ExecContext(ctx, query, telegramID, data, session.LastActivity.UTC())
+	return err
+}
+
+// DeleteSession removes a user's booking session
+func DeleteSession(ctx context.Context, db *sql.DB, telegramID int64) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM booking_sessions WHERE telegram_id = $1`, telegramID)
+	return err
+}
+
+// RemoveExpiredSessions deletes and returns sessions idle since before cutoff.
+// DELETE ... RETURNING hands each session to exactly one replica.
+func RemoveExpiredSessions(ctx context.Context, db *sql.DB, cutoff time.Time) ([]state.BookingSession, error) {
+	query := `DELETE FROM booking_sessions WHERE updated_at < $1 RETURNING data`
+
+	rows, err := db.QueryContext(ctx, query, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []state.BookingSession
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var session state.BookingSession
+		if err := json.Unmarshal(data, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// PostgresSessionManager implements state.SessionManager on the booking_sessions table
+type PostgresSessionManager struct {
+	pg    *Postgres
+	holds state.Holds
+}
+
+var _ state.SessionManager = (*PostgresSessionManager)(nil)
+
+// NewPostgresSessionManager creates a manager whose ClearSession also releases holds, if set
+func NewPostgresSessionManager(database *sql.DB, timeout time.Duration, holds state.Holds) *PostgresSessionManager {
+	return &PostgresSessionManager{pg: NewPostgres(database, timeout), holds: holds}
+}
+
+func (p *PostgresSessionManager) GetSession(ctx context.Context, userID int64) (*state.BookingSession, error) {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	return GetSession(ctx, p.pg.DB, userID)
+}
+
+func (p *PostgresSessionManager) SetSession(ctx context.Context, userID int64, session *state.BookingSession) error {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	return SaveSession(ctx, p.pg.DB, userID, session)
+}
+
+func (p *PostgresSessionManager) ClearSession(ctx context.Context, userID int64) error {
+	if p.holds != nil {
+		if err := p.holds.Release(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+	return DeleteSession(ctx, p.pg.DB, userID)
+}
+
+func (p *PostgresSessionManager) RemoveExpired(ctx context.Context, cutoff time.Time) ([]state.BookingSession, error) {
+	ctx, cancel := p.pg.withTimeout(ctx)
+	defer cancel()
+
+	return RemoveExpiredSessions(ctx, p.pg.DB, cutoff)
+}
@@ -0,0 +1,243 @@
+// db/store.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"telegrarmchatbot/internal/model"
+	"time"
+)
+
+type UserStore interface {
+	CreateOrGetUser(ctx context.Context, telegramID int64, username, fullName string) (*model.User, error)
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+}
+
+type RoomStore interface {
+	GetAllActiveRooms(ctx context.Context) ([]model.Room, error)
+	GetRoomByID(ctx context.Context, roomID int) (*model.Room, error)
+	GetRoomByName(ctx context.Context, roomName string) (*model.Room, error)
+	SeedRooms(ctx context.Context, rooms []model.Room) error
+}
+
+type BookingStore interface {
+	GetBookingsByDate(ctx context.Context, date time.Time) ([]model.Booking, error)
+	CreateBooking(ctx context.Context, booking *model.Booking, participants []model.Participants) error
+	GetUserBookings(ctx context.Context, userID int) ([]model.Booking, error)
+	CancelBooking(ctx context.Context, bookingID int, userID int) error
+	UpdateBookingTime(ctx context.Context, booking *model.Booking) error
+	CreateSeries(ctx context.Context, series *model.BookingSeries, occurrences []model.Booking, participants []model.Participants) (booked, conflicts []model.Booking, err error)
+	GetBookingByID(ctx context.Context, bookingID int) (*model.Booking, error)
+}
+
+type ReminderStore interface {
+	GetUpcomingBookings(ctx context.Context, from, to time.Time) ([]model.Booking, error)
+	GetParticipantTelegramIDs(ctx context.Context, bookingID int) ([]int64, error)
+	ClaimReminder(ctx context.Context, bookingID int, leadMinutes int) (bool, error)
+}
+
+type CheckInStore interface {
+	ClaimCheckInPrompt(ctx context.Context, bookingID int) (bool, error)
+	CheckIn(ctx context.Context, bookingID int, userID int) error
+	ReleaseNoShows(ctx context.Context, startedBefore time.Time) ([]model.Booking, error)
+	GetRoomUtilization(ctx context.Context, from, to time.Time) ([]model.RoomUtilization, error)
+}
+
+type WaitlistStore interface {
+	JoinWaitlist(ctx context.Context, entry *model.WaitlistEntry) (int, error)
+	OfferNextWaitlist(ctx context.Context, slot model.WaitlistEntry, expiresAt time.Time) (*model.WaitlistEntry, error)
+	ClaimWaitlistOffer(ctx context.Context, waitlistID int, telegramID int64, now time.Time) (*model.WaitlistEntry, error)
+	PassWaitlistOffer(ctx context.Context, waitlistID int, telegramID int64) (*model.WaitlistEntry, error)
+	ExpireWaitlistOffers(ctx context.Context, now time.Time) ([]model.WaitlistEntry, error)
+}
+
+// Store is everything the bot needs from persistence
+type Store interface {
+	UserStore
+	RoomStore
+	BookingStore
+	ReminderStore
+	CheckInStore
+	WaitlistStore
+}
+
+var (
+	_ Store = (*Postgres)(nil)
+	_ Store = (*Memory)(nil)
+)
+
+// Postgres implements Store on top of the package's query functions.
+// Every call is bounded by Timeout on top of the caller's context.
+type Postgres struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+func NewPostgres(database *sql.DB, timeout time.Duration) *Postgres {
+	return &Postgres{DB: database, Timeout: timeout}
+}
+
+func (p *Postgres) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.Timeout)
+}
+
+func (p *Postgres) CreateOrGetUser(ctx context.Context, telegramID int64, username, fullName string) (*model.User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return CreateOrGetUser(ctx, p.DB, telegramID, username, fullName)
+}
+
+func (p *Postgres) GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetUserByTelegramID(ctx, p.DB, telegramID)
+}
+
+func (p *Postgres) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetUserByUsername(ctx, p.DB, username)
+}
+
+func (p *Postgres) GetAllActiveRooms(ctx context.Context) ([]model.Room, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetAllActiveRooms(ctx, p.DB)
+}
+
+func (p *Postgres) GetRoomByID(ctx context.Context, roomID int) (*model.Room, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetRoomByID(ctx, p.DB, roomID)
+}
+
+func (p *Postgres) GetRoomByName(ctx context.Context, roomName string) (*model.Room, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetRoomByName(ctx, p.DB, roomName)
+}
+
+func (p *Postgres) SeedRooms(ctx context.Context, rooms []model.Room) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return SeedRooms(ctx, p.DB, rooms)
+}
+
+func (p *Postgres) GetBookingsByDate(ctx context.Context, date time.Time) ([]model.Booking, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetBookingsByDate(ctx, p.DB, date)
+}
+
+func (p *Postgres) CreateBooking(ctx context.Context, booking *model.Booking, participants []model.Participants) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return CreateBooking(ctx, p.DB, booking, participants)
+}
+
+func (p *Postgres) GetUserBookings(ctx context.Context, userID int) ([]model.Booking, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetUserBookings(ctx, p.DB, userID)
+}
+
+func (p *Postgres) CancelBooking(ctx context.Context, bookingID int, userID int) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return CancelBooking(ctx, p.DB, bookingID, userID)
+}
+
+func (p *Postgres) UpdateBookingTime(ctx context.Context, booking *model.Booking) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return UpdateBookingTime(ctx, p.DB, booking)
+}
+
+func (p *Postgres) CreateSeries(ctx context.Context, series *model.BookingSeries, occurrences []model.Booking, participants []model.Participants) ([]model.Booking, []model.Booking, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return CreateSeries(ctx, p.DB, series, occurrences, participants)
+}
+
+func (p *Postgres) GetBookingByID(ctx context.Context, bookingID int) (*model.Booking, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetBookingByID(ctx, p.DB, bookingID)
+}
+
+func (p *Postgres) GetUpcomingBookings(ctx context.Context, from, to time.Time) ([]model.Booking, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetUpcomingBookings(ctx, p.DB, from, to)
+}
+
+func (p *Postgres) GetParticipantTelegramIDs(ctx context.Context, bookingID int) ([]int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetParticipantTelegramIDs(ctx, p.DB, bookingID)
+}
+
+func (p *Postgres) ClaimReminder(ctx context.Context, bookingID int, leadMinutes int) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return ClaimReminder(ctx, p.DB, bookingID, leadMinutes)
+}
+
+func (p *Postgres) ClaimCheckInPrompt(ctx context.Context, bookingID int) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return ClaimCheckInPrompt(ctx, p.DB, bookingID)
+}
+
+func (p *Postgres) CheckIn(ctx context.Context, bookingID int, userID int) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return CheckIn(ctx, p.DB, bookingID, userID)
+}
+
+func (p *Postgres) ReleaseNoShows(ctx context.Context, startedBefore time.Time) ([]model.Booking, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return ReleaseNoShows(ctx, p.DB, startedBefore)
+}
+
+func (p *Postgres) GetRoomUtilization(ctx context.Context, from, to time.Time) ([]model.RoomUtilization, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return GetRoomUtilization(ctx, p.DB, from, to)
+}
+
+func (p *Postgres) JoinWaitlist(ctx context.Context, entry *model.WaitlistEntry) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return JoinWaitlist(ctx, p.DB, entry)
+}
+
+func (p *Postgres) OfferNextWaitlist(ctx context.Context, slot model.WaitlistEntry, expiresAt time.Time) (*model.WaitlistEntry, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return OfferNextWaitlist(ctx, p.DB, slot, expiresAt)
+}
+
+func (p *Postgres) ClaimWaitlistOffer(ctx context.Context, waitlistID int, telegramID int64, now time.Time) (*model.WaitlistEntry, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return ClaimWaitlistOffer(ctx, p.DB, waitlistID, telegramID, now)
+}
+
+func (p *Postgres) PassWaitlistOffer(ctx context.Context, waitlistID int, telegramID int64) (*model.WaitlistEntry, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return PassWaitlistOffer(ctx, p.DB, waitlistID, telegramID)
+}
+
+func (p *Postgres) ExpireWaitlistOffers(ctx context.Context, now time.Time) ([]model.WaitlistEntry, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return ExpireWaitlistOffers(ctx, p.DB, now)
+}
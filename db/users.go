@@ -1,20 +1,21 @@
-// db/user.go
+// db/users.go
 
 package db
 
 import (
+	"context"
 	"database/sql"
 	"telegrarmchatbot/internal/model"
 )
 
-func CreateOrGetUser(db *sql.DB, telegramID int64, username, fullName string) (*model.User, error) {
+func CreateOrGetUser(ctx context.Context, db *sql.DB, telegramID int64, username, fullName string) (*model.User, error) {
 	var user model.User
 	
 	// Try to get existing user
 	query := `SELECT user_id, telegram_id, username, fullname, create_at 
 	          FROM users WHERE telegram_id = $1`
 	
-	err := db.QueryRow(query, telegramID).Scan(
+	err := db.QueryRowContext(ctx, query, telegramID).Scan(
 		&user.UserID, &user.TelegramID, &user.Username, &user.FullName, &user.CreateAt,
 	)
 	
@@ -25,7 +26,7 @@ func CreateOrGetUser(db *sql.DB, telegramID int64, username, fullName string) (*
 		VALUES ($1, $2, $3) 
 		RETURNING user_id, telegram_id, username, fullname, create_at`
 		
-		err = db.QueryRow(insertQuery, telegramID, username, fullName).Scan(
+		err = db.QueryRowContext(ctx, insertQuery, telegramID, username, fullName).Scan(
 			&user.UserID, &user.TelegramID, &user.Username, &user.FullName, &user.CreateAt,
 		)
 		if err != nil {
@@ -33,17 +34,31 @@ func CreateOrGetUser(db *sql.DB, telegramID int64, username, fullName string) (*
 		}
 	} else if err != nil {
 		return nil, err
+	} else if username != "" && (user.Username != username || user.FullName != fullName) {
+		// Telegram usernames and names can change; keep them current so
+		// @username lookups still find the person
+		updateQuery := `
+		UPDATE users SET username = $2, fullname = $3
+		WHERE telegram_id = $1
+		RETURNING user_id, telegram_id, username, fullname, create_at`
+
+		err = db.QueryRowContext(ctx, updateQuery, telegramID, username, fullName).Scan(
+			&user.UserID, &user.TelegramID, &user.Username, &user.FullName, &user.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 	
 	return &user, nil
 }
 
-func GetUserByTelegramID(db *sql.DB, telegramID int64) (*model.User, error) {
+func GetUserByTelegramID(ctx context.Context, db *sql.DB, telegramID int64) (*model.User, error) {
 	var user model.User
 	query := `SELECT user_id, telegram_id, username, fullname, create_at 
 	          FROM users WHERE telegram_id = $1`
 	
-	err := db.QueryRow(query, telegramID).Scan(
+	err := db.QueryRowContext(ctx, query, telegramID).Scan(
 		&user.UserID, &user.TelegramID, &user.Username, &user.FullName, &user.CreateAt,
 	)
 	
@@ -52,4 +67,22 @@ func GetUserByTelegramID(db *sql.DB, telegramID int64) (*model.User, error) {
 	}
 	
 	return &user, nil
-}
\ No newline at end of file
+}
+
+// GetUserByUsername finds a user by Telegram username, ignoring case and a leading @.
+// It returns sql.ErrNoRows if nobody with that username has used the bot.
+func GetUserByUsername(ctx context.Context, db *sql.DB, username string) (*model.User, error) {
+	var user model.User
+	query := `SELECT user_id, telegram_id, username, fullname, create_at
+	          FROM users WHERE username <> '' AND lower(username) = lower(ltrim($1, '@'))
+	          ORDER BY user_id LIMIT 1`
+
+	err := db.QueryRowContext(ctx, query, username).Scan(
+		&user.UserID, &user.TelegramID, &user.Username, &user.FullName, &user.CreateAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
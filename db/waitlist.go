@@ -0,0 +1,171 @@
+// db/waitlist.go
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"telegrarmchatbot/internal/model"
+	"time"
+)
+
+// ErrNoOffer is returned when a waitlist offer was not made to the user, already answered or expired
+var ErrNoOffer = errors.New("waitlist offer not found or expired")
+
+// waitlistResult selects the entries changed by the CTE named "changed", with their room name and telegram ID
+const waitlistResult = `
+	SELECT w.waitlist_id, w.room_id, w.user_id, w.date, w.start_time, w.end_time,
+	       w.status, w.create_at, r.room_name, u.telegram_id
+	FROM changed w
+	JOIN rooms r ON w.room_id = r.room_id
+	JOIN users u ON w.user_id = u.user_id
+	ORDER BY w.waitlist_id`
+
+// Waitlist slots are compared as text so the DATE and TIME columns see local wall-clock values
+func slotArgs(entry model.WaitlistEntry) []any {
+	return []any{entry.RoomID, entry.Date.Format("2006-01-02"), entry.StartTime.Format("15:04"), entry.EndTime.Format("15:04")}
+}
+
+func queryWaitlist(ctx context.Context, db *sql.DB, query string, args ...any) ([]model.WaitlistEntry, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.WaitlistEntry
+	for rows.Next() {
+		var entry model.WaitlistEntry
+		err := rows.Scan(
+			&entry.WaitlistID, &entry.RoomID, &entry.UserID, &entry.Date, &entry.StartTime, &entry.EndTime,
+			&entry.Status, &entry.CreateAt, &entry.RoomName, &entry.TelegramID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// JoinWaitlist adds the user to the waitlist for entry's room, date and time range
+// and returns their place in the queue. Joining twice keeps the original place.
+func JoinWaitlist(ctx context.Context, db *sql.DB, entry *model.WaitlistEntry) (int, error) {
+	args := append(slotArgs(*entry), entry.UserID)
+
+	query := `
+	INSERT INTO waitlist (room_id, date, start_time, end_time, user_id)
+	VALUES ($1, $2::date, $3::time, $4::time, $5)
+	ON CONFLICT DO NOTHING`
+
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		return 0, err
+	}
+
+	positionQuery := `
+	SELECT count(*) FROM waitlist
+	WHERE room_id = $1 AND date = $2::date AND start_time = $3::time AND end_time = $4::time
+	AND status IN ('WAITING', 'OFFERED')
+	AND waitlist_id <= (
+		SELECT waitlist_id FROM waitlist
+		WHERE room_id = $1 AND date = $2::date AND start_time = $3::time AND end_time = $4::time
+		AND user_id = $5 AND status IN ('WAITING', 'OFFERED')
+	)`
+
+	var position int
+	err := db.QueryRowContext(ctx, positionQuery, args...).Scan(&position)
+	return position, err
+}
+
+// OfferNextWaitlist offers slot's room, date and time range to the first waiting user
+// until expiresAt. It returns nil if nobody is waiting or an offer is already open.
+func OfferNextWaitlist(ctx context.Context, db *sql.DB, slot model.WaitlistEntry, expiresAt time.Time) (*model.WaitlistEntry, error) {
+	args := append(slotArgs(slot), expiresAt.Format("2006-01-02 15:04:05"))
+
+	query := `
+	WITH next AS (
+		SELECT waitlist_id FROM waitlist
+		WHERE room_id = $1 AND date = $2::date AND start_time = $3::time AND end_time = $4::time
+		AND status = 'WAITING'
+		AND NOT EXISTS (
+			SELECT 1 FROM waitlist
+			WHERE room_id = $1 AND date = $2::date AND start_time = $3::time AND end_time = $4::time
+			AND status = 'OFFERED'
+		)
+		ORDER BY waitlist_id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	), changed AS (
+		UPDATE waitlist w
+		SET status = 'OFFERED', offer_expires_at = $5::timestamp
+		FROM next
+		WHERE w.waitlist_id = next.waitlist_id
+		RETURNING w.*
+	)` + waitlistResult
+
+	entries, err := queryWaitlist(ctx, db, query, args...)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	entries[0].OfferExpiresAt = expiresAt
+	return &entries[0], nil
+}
+
+// ClaimWaitlistOffer accepts an open offer made to telegramID.
+// It returns ErrNoOffer if there is no such offer or it expired before now.
+func ClaimWaitlistOffer(ctx context.Context, db *sql.DB, waitlistID int, telegramID int64, now time.Time) (*model.WaitlistEntry, error) {
+	query := `
+	WITH changed AS (
+		UPDATE waitlist
+		SET status = 'CLAIMED'
+		WHERE waitlist_id = $1 AND status = 'OFFERED' AND offer_expires_at > $3::timestamp
+		AND user_id = (SELECT user_id FROM users WHERE telegram_id = $2)
+		RETURNING *
+	)` + waitlistResult
+
+	entries, err := queryWaitlist(ctx, db, query, waitlistID, telegramID, now.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoOffer
+	}
+	return &entries[0], nil
+}
+
+// PassWaitlistOffer declines an open offer made to telegramID, so it can go to the next user
+func PassWaitlistOffer(ctx context.Context, db *sql.DB, waitlistID int, telegramID int64) (*model.WaitlistEntry, error) {
+	query := `
+	WITH changed AS (
+		UPDATE waitlist
+		SET status = 'PASSED'
+		WHERE waitlist_id = $1 AND status = 'OFFERED'
+		AND user_id = (SELECT user_id FROM users WHERE telegram_id = $2)
+		RETURNING *
+	)` + waitlistResult
+
+	entries, err := queryWaitlist(ctx, db, query, waitlistID, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoOffer
+	}
+	return &entries[0], nil
+}
+
+// ExpireWaitlistOffers closes the offers not answered by now and returns them
+func ExpireWaitlistOffers(ctx context.Context, db *sql.DB, now time.Time) ([]model.WaitlistEntry, error) {
+	query := `
+	WITH changed AS (
+		UPDATE waitlist
+		SET status = 'EXPIRED'
+		WHERE status = 'OFFERED' AND offer_expires_at <= $1::timestamp
+		RETURNING *
+	)` + waitlistResult
+
+	return queryWaitlist(ctx, db, query, now.Format("2006-01-02 15:04:05"))
+}
@@ -4,4 +4,6 @@ go 1.25.5
 
 require github.com/go-telegram/bot v1.18.0
 
-require github.com/lib/pq v1.11.2 // indirect
+require github.com/lib/pq v1.11.2
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,247 @@
+// internal/config/config.go
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is read when CONFIG_FILE is not set. A missing file is not an
+// error; everything can come from the defaults and environment instead.
+const DefaultPath = "config.yaml"
+
+type Config struct {
+	Telegram  TelegramConfig `yaml:"telegram"`
+	Database  DatabaseConfig `yaml:"database"`
+	Rooms     []RoomConfig   `yaml:"rooms"`
+	Booking   BookingConfig  `yaml:"booking"`
+	Sessions  SessionConfig  `yaml:"sessions"`
+	Reminders ReminderConfig `yaml:"reminders"`
+}
+
+type TelegramConfig struct {
+	Token string `yaml:"-"` // env TELEGRAM_TOKEN only, so the token is never kept in a file
+}
+
+type DatabaseConfig struct {
+	URL          string `yaml:"url"`           // env DATABASE_URL
+	QueryTimeout int    `yaml:"query_timeout"` // seconds each query may take
+}
+
+// QueryTimeoutDuration is QueryTimeout as a time.Duration
+func (d DatabaseConfig) QueryTimeoutDuration() time.Duration {
+	return time.Duration(d.QueryTimeout) * time.Second
+}
+
+// SessionConfig selects where in-progress bookings are kept
+type SessionConfig struct {
+	Backend string `yaml:"backend"` // "memory" or "postgres"; postgres survives restarts and is shared by replicas
+	TTL     int    `yaml:"ttl"`     // minutes of inactivity before a booking conversation is cancelled
+}
+
+// TTLDuration is TTL as a time.Duration
+func (s SessionConfig) TTLDuration() time.Duration {
+	return time.Duration(s.TTL) * time.Minute
+}
+
+// ReminderConfig controls the messages sent before a meeting starts
+type ReminderConfig struct {
+	LeadTimes []int `yaml:"lead_times"` // minutes before the start to remind the organizer and participants; empty disables reminders
+}
+
+// RoomConfig is a room seeded into the rooms table at startup
+type RoomConfig struct {
+	Name     string `yaml:"name"`
+	Capacity int    `yaml:"capacity"`
+}
+
+type BookingConfig struct {
+	WorkdayStart  string `yaml:"workday_start"`
+	WorkdayEnd    string `yaml:"workday_end"`
+	SlotDuration  int    `yaml:"slot_duration"`  // minutes, 30 or 60; bookings span one or more consecutive slots
+	HoldDuration  int    `yaml:"hold_duration"`  // minutes a chosen slot stays reserved after the user's last answer
+	CheckInGrace  int    `yaml:"check_in_grace"` // minutes after the start to check in before the room is released; 0 turns check-in off
+	WaitlistOffer int    `yaml:"waitlist_offer"` // minutes a freed slot is offered to the next waiting user
+
+	// RoomHours overrides the workday for specific rooms, keyed by room name
+	RoomHours map[string]OperatingHours `yaml:"room_hours"`
+	// WeekdayHours overrides the workday for specific days, keyed by lowercase weekday name, e.g. "friday"
+	WeekdayHours map[string]OperatingHours `yaml:"weekday_hours"`
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Database:  DatabaseConfig{QueryTimeout: 5},
+		Sessions:  SessionConfig{Backend: "memory", TTL: 15},
+		Reminders: ReminderConfig{LeadTimes: []int{15, 5}},
+		Rooms: []RoomConfig{
+			{Name: "Room A", Capacity: 10},
+			{Name: "Room B", Capacity: 10},
+			{Name: "Room C", Capacity: 10},
+		},
+		Booking: BookingConfig{
+			WorkdayStart:  "09:00",
+			WorkdayEnd:    "17:00",
+			SlotDuration:  60,
+			HoldDuration:  10,
+			CheckInGrace:  10,
+			WaitlistOffer: 15,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at
+// CONFIG_FILE (or DefaultPath) and the TELEGRAM_TOKEN and DATABASE_URL
+// environment variables, in that order, then validates it.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) || os.Getenv("CONFIG_FILE") != "" {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	// Weekday keys are matched in lowercase
+	weekdayHours := make(map[string]OperatingHours)
+	for day, hours := range cfg.Booking.WeekdayHours {
+		weekdayHours[strings.ToLower(day)] = hours
+	}
+	cfg.Booking.WeekdayHours = weekdayHours
+
+	if token := os.Getenv("TELEGRAM_TOKEN"); token != "" {
+		cfg.Telegram.Token = token
+	}
+	if url := os.Getenv("DATABASE_URL"); url != "" {
+		cfg.Database.URL = url
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// ValidateTelegram checks the settings only the bot itself needs, so that
+// maintenance commands like migrate can run without a token.
+func (c *Config) ValidateTelegram() error {
+	if c.Telegram.Token == "" {
+		return errors.New("config: telegram token is required (TELEGRAM_TOKEN)")
+	}
+	return nil
+}
+
+// Validate reports the first problem that would stop the bot from working
+func (c *Config) Validate() error {
+	if c.Database.URL == "" {
+		return errors.New("config: database url is required (DATABASE_URL)")
+	}
+
+	if c.Database.QueryTimeout <= 0 {
+		return fmt.Errorf("config: query_timeout must be positive, got %d", c.Database.QueryTimeout)
+	}
+
+	if c.Sessions.Backend != "memory" && c.Sessions.Backend != "postgres" {
+		return fmt.Errorf("config: sessions backend must be memory or postgres, got %q", c.Sessions.Backend)
+	}
+	if c.Sessions.TTL <= 0 {
+		return fmt.Errorf("config: sessions ttl must be positive, got %d", c.Sessions.TTL)
+	}
+
+	for _, lead := range c.Reminders.LeadTimes {
+		if lead <= 0 {
+			return fmt.Errorf("config: reminder lead_times must be positive, got %d", lead)
+		}
+	}
+
+	if len(c.Rooms) == 0 {
+		return errors.New("config: at least one room is required")
+	}
+	seen := make(map[string]bool)
+	for _, room := range c.Rooms {
+		if room.Name == "" {
+			return errors.New("config: room name is required")
+		}
+		if seen[room.Name] {
+			return fmt.Errorf("config: duplicate room %q", room.Name)
+		}
+		seen[room.Name] = true
+	}
+
+	b := c.Booking
+	// Shorter slots would make the time pickers longer than a message can show
+	if b.SlotDuration != 30 && b.SlotDuration != 60 {
+		return fmt.Errorf("config: slot_duration must be 30 or 60, got %d", b.SlotDuration)
+	}
+	if b.HoldDuration <= 0 {
+		return fmt.Errorf("config: hold_duration must be positive, got %d", b.HoldDuration)
+	}
+	if b.CheckInGrace < 0 {
+		return fmt.Errorf("config: check_in_grace must not be negative, got %d", b.CheckInGrace)
+	}
+	if b.WaitlistOffer <= 0 {
+		return fmt.Errorf("config: waitlist_offer must be positive, got %d", b.WaitlistOffer)
+	}
+	if err := validateHours("workday", OperatingHours{Start: b.WorkdayStart, End: b.WorkdayEnd}); err != nil {
+		return err
+	}
+	for name, hours := range b.RoomHours {
+		if err := validateHours(name, hours); err != nil {
+			return err
+		}
+	}
+	for day, hours := range b.WeekdayHours {
+		if _, ok := parseWeekday(day); !ok {
+			return fmt.Errorf("config: unknown weekday %q", day)
+		}
+		if err := validateHours(day, hours); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateHours(name string, hours OperatingHours) error {
+	var start, end time.Time
+	var err error
+	if hours.Start != "" {
+		if start, err = time.Parse("15:04", hours.Start); err != nil {
+			return fmt.Errorf("config: %s start %q is not HH:MM", name, hours.Start)
+		}
+	}
+	if hours.End != "" {
+		if end, err = time.Parse("15:04", hours.End); err != nil {
+			return fmt.Errorf("config: %s end %q is not HH:MM", name, hours.End)
+		}
+	}
+	if hours.Start != "" && hours.End != "" && !start.Before(end) {
+		return fmt.Errorf("config: %s start %s is not before end %s", name, hours.Start, hours.End)
+	}
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
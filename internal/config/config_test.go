@@ -0,0 +1,116 @@
+// internal/config/config_test.go
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string // empty when the config is valid
+	}{
+		{
+			name:   "defaults with a database",
+			modify: func(c *Config) {},
+		},
+		{
+			name:    "missing database url",
+			modify:  func(c *Config) { c.Database.URL = "" },
+			wantErr: "database url is required",
+		},
+		{
+			name:    "zero query timeout",
+			modify:  func(c *Config) { c.Database.QueryTimeout = 0 },
+			wantErr: "query_timeout must be positive",
+		},
+		{
+			name:    "unknown sessions backend",
+			modify:  func(c *Config) { c.Sessions.Backend = "redis" },
+			wantErr: "sessions backend must be memory or postgres",
+		},
+		{
+			name:    "negative reminder lead time",
+			modify:  func(c *Config) { c.Reminders.LeadTimes = []int{15, -5} },
+			wantErr: "lead_times must be positive",
+		},
+		{
+			name:   "no reminders",
+			modify: func(c *Config) { c.Reminders.LeadTimes = nil },
+		},
+		{
+			name:    "no rooms",
+			modify:  func(c *Config) { c.Rooms = nil },
+			wantErr: "at least one room is required",
+		},
+		{
+			name:    "duplicate room",
+			modify:  func(c *Config) { c.Rooms = append(c.Rooms, RoomConfig{Name: "Room A"}) },
+			wantErr: `duplicate room "Room A"`,
+		},
+		{
+			name:   "half hour slots",
+			modify: func(c *Config) { c.Booking.SlotDuration = 30 },
+		},
+		{
+			name:    "slot not dividing an hour",
+			modify:  func(c *Config) { c.Booking.SlotDuration = 45 },
+			wantErr: "slot_duration must be 30 or 60",
+		},
+		{
+			name:    "slots too short",
+			modify:  func(c *Config) { c.Booking.SlotDuration = 5 },
+			wantErr: "slot_duration must be 30 or 60",
+		},
+		{
+			name:   "check-in turned off",
+			modify: func(c *Config) { c.Booking.CheckInGrace = 0 },
+		},
+		{
+			name:    "negative check-in grace",
+			modify:  func(c *Config) { c.Booking.CheckInGrace = -1 },
+			wantErr: "check_in_grace must not be negative",
+		},
+		{
+			name:    "workday ends before it starts",
+			modify:  func(c *Config) { c.Booking.WorkdayStart, c.Booking.WorkdayEnd = "17:00", "09:00" },
+			wantErr: "workday start 17:00 is not before end 09:00",
+		},
+		{
+			name:    "bad room hours",
+			modify:  func(c *Config) { c.Booking.RoomHours = map[string]OperatingHours{"Room A": {Start: "9am"}} },
+			wantErr: `Room A start "9am" is not HH:MM`,
+		},
+		{
+			name:   "closed weekday",
+			modify: func(c *Config) { c.Booking.WeekdayHours = map[string]OperatingHours{"sunday": {Closed: true}} },
+		},
+		{
+			name:    "unknown weekday",
+			modify:  func(c *Config) { c.Booking.WeekdayHours = map[string]OperatingHours{"funday": {Closed: true}} },
+			wantErr: `unknown weekday "funday"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Database.URL = "postgres://localhost/booking"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,19 +1,80 @@
-// internal/config/rooms.go - NEW FILE
+// internal/config/rooms.go
 
 package config
 
-var (
-	RoomNames     = []string{"Room A", "Room B", "Room C"}
-	WorkdayStart  = "09:00"
-	WorkdayEnd    = "17:00"
-	SlotDuration  = 60 // minutes
+import (
+	"strings"
+	"time"
 )
 
-// GenerateTimeSlots creates hourly slots from 09:00 to 17:00
-func GenerateTimeSlots() []string {
-	slots := []string{
-		"09:00", "10:00", "11:00", "12:00", 
-		"13:00", "14:00", "15:00", "16:00",
+// OperatingHours is an override of the default workday. Empty fields keep the
+// value from the level below it.
+type OperatingHours struct {
+	Start  string `yaml:"start"`
+	End    string `yaml:"end"`
+	Closed bool   `yaml:"closed"`
+}
+
+// TimeSlot is one bookable slot, as "15:04" strings
+type TimeSlot struct {
+	Start string
+	End   string
+}
+
+// HoursFor resolves the opening hours of a room on a date: the workday,
+// then the room's override, then the weekday's override.
+func (b BookingConfig) HoursFor(roomName string, date time.Time) OperatingHours {
+	hours := OperatingHours{Start: b.WorkdayStart, End: b.WorkdayEnd}
+	weekday := b.WeekdayHours[strings.ToLower(date.Weekday().String())]
+	for _, override := range []OperatingHours{b.RoomHours[roomName], weekday} {
+		if override.Start != "" {
+			hours.Start = override.Start
+		}
+		if override.End != "" {
+			hours.End = override.End
+		}
+		if override.Closed {
+			hours.Closed = true
+		}
+	}
+	return hours
+}
+
+// GenerateTimeSlots returns every full SlotDuration slot within the room's opening hours on date
+func (b BookingConfig) GenerateTimeSlots(roomName string, date time.Time) []TimeSlot {
+	hours := b.HoursFor(roomName, date)
+	if hours.Closed {
+		return nil
+	}
+
+	start, err := time.Parse("15:04", hours.Start)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("15:04", hours.End)
+	if err != nil {
+		return nil
+	}
+	step := time.Duration(b.SlotDuration) * time.Minute
+
+	var slots []TimeSlot
+	for t := start; !t.Add(step).After(end); t = t.Add(step) {
+		slots = append(slots, TimeSlot{Start: t.Format("15:04"), End: t.Add(step).Format("15:04")})
 	}
 	return slots
-}
\ No newline at end of file
+}
+
+// HoldTTL is how long a chosen slot stays reserved
+func (b BookingConfig) HoldTTL() time.Duration {
+	return time.Duration(b.HoldDuration) * time.Minute
+}
+
+// CheckInGraceDuration is how long after a meeting starts the organizer has to check in
+func (b BookingConfig) CheckInGraceDuration() time.Duration {
+	return time.Duration(b.CheckInGrace) * time.Minute
+}
+
+// WaitlistOfferDuration is how long a waiting user has to claim a freed slot
+func (b BookingConfig) WaitlistOfferDuration() time.Duration {
+	return time.Duration(b.WaitlistOffer) * time.Minute
+}
@@ -0,0 +1,98 @@
+// internal/config/rooms_test.go
+
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// Monday 19 October 2026 and the Friday after it
+var (
+	monday = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	friday = time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)
+)
+
+// testBooking opens 09:00-12:00 with the given slots and overrides
+func testBooking(slotDuration int, roomHours, weekdayHours map[string]OperatingHours) BookingConfig {
+	return BookingConfig{
+		WorkdayStart: "09:00",
+		WorkdayEnd:   "12:00",
+		SlotDuration: slotDuration,
+		RoomHours:    roomHours,
+		WeekdayHours: weekdayHours,
+	}
+}
+
+func TestHoursFor(t *testing.T) {
+	roomHours := map[string]OperatingHours{"Room B": {Start: "10:00"}}
+	weekdayHours := map[string]OperatingHours{
+		"friday":   {End: "11:00"},
+		"saturday": {Closed: true},
+	}
+
+	tests := []struct {
+		name string
+		room string
+		date time.Time
+		want OperatingHours
+	}{
+		{"workday", "Room A", monday, OperatingHours{Start: "09:00", End: "12:00"}},
+		{"room override", "Room B", monday, OperatingHours{Start: "10:00", End: "12:00"}},
+		{"weekday override", "Room A", friday, OperatingHours{Start: "09:00", End: "11:00"}},
+		{"room and weekday overrides combine", "Room B", friday, OperatingHours{Start: "10:00", End: "11:00"}},
+		{"closed weekday", "Room A", friday.AddDate(0, 0, 1), OperatingHours{Start: "09:00", End: "12:00", Closed: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBooking(60, roomHours, weekdayHours)
+			if got := b.HoursFor(tt.room, tt.date); got != tt.want {
+				t.Errorf("HoursFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTimeSlots(t *testing.T) {
+	tests := []struct {
+		name         string
+		slotDuration int
+		roomHours    map[string]OperatingHours
+		want         []TimeSlot
+	}{
+		{
+			name:         "hour slots",
+			slotDuration: 60,
+			want:         []TimeSlot{{"09:00", "10:00"}, {"10:00", "11:00"}, {"11:00", "12:00"}},
+		},
+		{
+			name:         "half hour slots",
+			slotDuration: 30,
+			roomHours:    map[string]OperatingHours{"Room A": {Start: "10:30"}},
+			want:         []TimeSlot{{"10:30", "11:00"}, {"11:00", "11:30"}, {"11:30", "12:00"}},
+		},
+		{
+			name:         "only full slots",
+			slotDuration: 60,
+			roomHours:    map[string]OperatingHours{"Room A": {End: "11:30"}},
+			want:         []TimeSlot{{"09:00", "10:00"}, {"10:00", "11:00"}},
+		},
+		{
+			name:         "closed",
+			slotDuration: 60,
+			roomHours:    map[string]OperatingHours{"Room A": {Closed: true}},
+			want:         nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBooking(tt.slotDuration, tt.roomHours, nil)
+			if got := b.GenerateTimeSlots("Room A", monday); !slices.Equal(got, tt.want) {
+				t.Errorf("GenerateTimeSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
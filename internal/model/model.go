@@ -43,27 +43,81 @@ type Booking struct {
 	EndTime   time.Time `json:"end_time"`
 	Status    string    `json:"status"`
 	CreateAt  time.Time `json:"create_at"`
+	SeriesID  *int      `json:"series_id,omitempty"` // set when the booking is one occurrence of a BookingSeries
 
 	//join fields
 	RoomName     string   `json:"room_name,omitempty"`
 	Username     string   `json:"username,omitempty"`
 	FullName     string   `json:"fullname,omitempty"`
+	TelegramID   int64    `json:"telegram_id,omitempty"` // organizer's
 	Participants []string `json:"participants,omitempty"`
 }
 
+// Recurrence frequencies of a BookingSeries
+const (
+	FrequencyDaily   = "DAILY"
+	FrequencyWeekly  = "WEEKLY"
+	FrequencyMonthly = "MONTHLY"
+)
+
+// BookingSeries is a recurring meeting. Its occurrences are stored as bookings with SeriesID set.
+// The series ends at UntilDate, or after Count occurrences when UntilDate is zero.
+type BookingSeries struct {
+	SeriesID  int            `json:"series_id"`
+	UserID    int            `json:"user_id"`
+	RoomID    int            `json:"room_id"`
+	Topic     string         `json:"topic"`
+	Frequency string         `json:"frequency"`
+	Weekdays  []time.Weekday `json:"weekdays,omitempty"` // WEEKLY only
+	StartDate time.Time      `json:"start_date"`
+	UntilDate time.Time      `json:"until_date"`
+	Count     int            `json:"occurrence_count"`
+	StartTime time.Time      `json:"start_time"`
+	EndTime   time.Time      `json:"end_time"`
+	CreateAt  time.Time      `json:"create_at"`
+}
+
+// WaitlistEntry is a user waiting for a booked time range to free up
+type WaitlistEntry struct {
+	WaitlistID     int       `json:"waitlist_id"`
+	RoomID         int       `json:"room_id"`
+	UserID         int       `json:"user_id"`
+	Date           time.Time `json:"date"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Status         string    `json:"status"` // WAITING, OFFERED, CLAIMED, PASSED or EXPIRED
+	OfferExpiresAt time.Time `json:"offer_expires_at"`
+	CreateAt       time.Time `json:"create_at"`
+
+	//join fields
+	RoomName   string `json:"room_name,omitempty"`
+	TelegramID int64  `json:"telegram_id,omitempty"`
+}
+
 type Participants struct {
 	ParticipantID int    `json:"participant_id"`
 	BookingID     int    `json:"booking_id"`
 	Name          string `json:"name"`
+	UserID        *int   `json:"user_id,omitempty"` // set when the participant is a Telegram user
 }
 
 type TimeSlot struct {
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
 	IsFree    bool      `json:"is_free"`
+	IsHeld    bool      `json:"is_held"`
+	IsPast    bool      `json:"is_past"` // already started, so it can't be booked
 	Booking   *Booking  `json:"booking,omitempty"`
 }
 
+// RoomUtilization counts how bookings of a room were used, from booking_events
+type RoomUtilization struct {
+	RoomID    int    `json:"room_id"`
+	RoomName  string `json:"room_name"`
+	CheckedIn int    `json:"checked_in"`
+	NoShows   int    `json:"no_shows"`
+}
+
 type RoomSchedule struct {
 	RoomID    int        `json:"room_id"`
 	RoomName  string     `json:"room_name"`
@@ -1,59 +1,61 @@
-// internal/service/booking.go - UPDATE
+// internal/service/booking.go
 
 package service
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"strings"
 	"telegrarmchatbot/db"
 	"telegrarmchatbot/internal/config"
 	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/state"
 	"time"
+
+	"github.com/go-telegram/bot"
 )
 
 type BookingService struct {
-	DB *sql.DB
+	Rooms    db.RoomStore
+	Bookings db.BookingStore
+	Holds    state.Holds
+	Config   config.BookingConfig
+	Now      func() time.Time
 }
 
-func NewBookingService(database *sql.DB) *BookingService {
-	return &BookingService{DB: database}
+func NewBookingService(rooms db.RoomStore, bookings db.BookingStore, holds state.Holds, cfg config.BookingConfig, now func() time.Time) *BookingService {
+	return &BookingService{Rooms: rooms, Bookings: bookings, Holds: holds, Config: cfg, Now: now}
 }
 
 // GenerateTodayTimetable creates a full schedule for all rooms for today
-func (s *BookingService) GenerateTodayTimetable() ([]model.RoomSchedule, error) {
-	today := time.Now()
-	return s.GenerateTimetableForDate(today)
+func (s *BookingService) GenerateTodayTimetable(ctx context.Context) ([]model.RoomSchedule, error) {
+	today := s.Now()
+	return s.GenerateTimetableForDate(ctx, today)
 }
 
 // GenerateTimetableForDate creates schedule for a specific date
-func (s *BookingService) GenerateTimetableForDate(date time.Time) ([]model.RoomSchedule, error) {
+func (s *BookingService) GenerateTimetableForDate(ctx context.Context, date time.Time) ([]model.RoomSchedule, error) {
 	// Get all active rooms
-	rooms, err := db.GetAllActiveRooms(s.DB)
+	rooms, err := s.Rooms.GetAllActiveRooms(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get all bookings for this date
-	bookings, err := db.GetBookingsByDate(s.DB, date)
+	bookings, err := s.Bookings.GetBookingsByDate(ctx, date)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a map for quick lookup: roomID -> startTime -> booking
-	bookingMap := make(map[int]map[string]*model.Booking)
-	for _, room := range rooms {
-		bookingMap[room.RoomID] = make(map[string]*model.Booking)
-	}
-
+	// Group bookings by room; a booking may cover several consecutive slots
+	bookingMap := make(map[int][]*model.Booking)
 	for i := range bookings {
 		booking := &bookings[i]
-		bookingMap[booking.RoomID][booking.StartTime.Format("15:04")] = booking
+		bookingMap[booking.RoomID] = append(bookingMap[booking.RoomID], booking)
 	}
 
 	// Generate schedules for all rooms
 	var schedules []model.RoomSchedule
-	timeSlots := config.GenerateTimeSlots()
 
 	for _, room := range rooms {
 		schedule := model.RoomSchedule{
@@ -63,9 +65,10 @@ func (s *BookingService) GenerateTimetableForDate(date time.Time) ([]model.RoomS
 			TimeSlots: []model.TimeSlot{},
 		}
 
-		for i := 0; i < len(timeSlots)-1; i++ {
-			startStr := timeSlots[i]
-			endStr := timeSlots[i+1]
+		// Slots follow each room's own opening hours for this weekday
+		for _, timeSlot := range s.Config.GenerateTimeSlots(room.RoomName, date) {
+			startStr := timeSlot.Start
+			endStr := timeSlot.End
 
 			// Parse time strings (e.g. "09:00") and attach the provided date
 			tStart, err := time.Parse("15:04", startStr)
@@ -85,10 +88,20 @@ func (s *BookingService) GenerateTimetableForDate(date time.Time) ([]model.RoomS
 				EndTime:   endTime,
 			}
 
-			// Check if this slot is booked (bookingMap keys are in "15:04" format)
-			if booking, exists := bookingMap[room.RoomID][startTime.Format("15:04")]; exists {
+			// Check if any booking overlaps this slot
+			if booking := findOverlapping(bookingMap[room.RoomID], startStr, endStr); booking != nil {
 				slot.IsFree = false
 				slot.Booking = booking
+			} else if startTime.Before(s.Now()) {
+				// Too late to book; it would also miss its reminders and check-in
+				slot.IsFree = false
+				slot.IsPast = true
+			} else if _, held, err := s.Holds.HeldBy(ctx, room.RoomID, date, startStr, endStr); err != nil {
+				return nil, err
+			} else if held {
+				// Someone is in the middle of booking this slot
+				slot.IsFree = false
+				slot.IsHeld = true
 			} else {
 				slot.IsFree = true
 			}
@@ -102,28 +115,68 @@ func (s *BookingService) GenerateTimetableForDate(date time.Time) ([]model.RoomS
 	return schedules, nil
 }
 
-// FormatTimetableMessage converts schedules to Telegram message
+// findOverlapping returns the booking covering any part of startStr-endStr.
+// Times are "15:04" strings, which compare correctly as text.
+func findOverlapping(bookings []*model.Booking, startStr, endStr string) *model.Booking {
+	for _, booking := range bookings {
+		if booking.StartTime.Format("15:04") < endStr && booking.EndTime.Format("15:04") > startStr {
+			return booking
+		}
+	}
+	return nil
+}
+
+// GetRoomSchedule returns the schedule of a single room for a specific date
+func (s *BookingService) GetRoomSchedule(ctx context.Context, roomID int, date time.Time) (*model.RoomSchedule, error) {
+	schedules, err := s.GenerateTimetableForDate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range schedules {
+		if schedules[i].RoomID == roomID {
+			return &schedules[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("room %d not found", roomID)
+}
+
+// FormatTimetableMessage converts schedules to a MarkdownV2 Telegram message.
+// Like the other Format* messages, all user-entered text in it is escaped.
 func (s *BookingService) FormatTimetableMessage(schedules []model.RoomSchedule) string {
 	if len(schedules) == 0 {
-		return "No schedule available."
+		return "No schedule available\\."
 	}
 
 	date := schedules[0].Date.Format("02 Jan 2006")
-	message := fmt.Sprintf("📅 *Room Schedule - %s*\n\n", date)
+	message := fmt.Sprintf("📅 *Room Schedule \\- %s*\n\n", date)
 
 	for _, schedule := range schedules {
-		message += fmt.Sprintf("🏢 *%s*\n", schedule.RoomName)
+		message += fmt.Sprintf("🏢 *%s*\n", bot.EscapeMarkdown(schedule.RoomName))
+		if len(schedule.TimeSlots) == 0 {
+			message += "  🚫 Closed\n"
+		}
+
+		for i, slot := range schedule.TimeSlots {
+			// Consecutive slots of one booking are shown once, with the booking's own times
+			if slot.Booking != nil && i > 0 && schedule.TimeSlots[i-1].Booking == slot.Booking {
+				continue
+			}
 
-		for _, slot := range schedule.TimeSlots {
 			if slot.IsFree {
-				message += fmt.Sprintf("  ✅ %s-%s FREE\n", slot.StartTime.Format("15:04"), slot.EndTime.Format("15:04"))
+				message += fmt.Sprintf("  ✅ %s\\-%s FREE\n", slot.StartTime.Format("15:04"), slot.EndTime.Format("15:04"))
+			} else if slot.IsPast {
+				message += fmt.Sprintf("  ⌛ %s\\-%s PAST\n", slot.StartTime.Format("15:04"), slot.EndTime.Format("15:04"))
+			} else if slot.IsHeld {
+				message += fmt.Sprintf("  ⏳ %s\\-%s HELD\n", slot.StartTime.Format("15:04"), slot.EndTime.Format("15:04"))
 			} else {
 				booking := slot.Booking
-				message += fmt.Sprintf("  ❌ %s-%s BOOKED\n", slot.StartTime.Format("15:04"), slot.EndTime.Format("15:04"))
-				message += fmt.Sprintf("     👤 By: %s\n", booking.FullName)
-				message += fmt.Sprintf("     📝 %s\n", booking.Topic)
+				message += fmt.Sprintf("  ❌ %s\\-%s BOOKED\n", booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
+				message += fmt.Sprintf("     👤 By: %s\n", bot.EscapeMarkdown(booking.FullName))
+				message += fmt.Sprintf("     📝 %s\n", bot.EscapeMarkdown(booking.Topic))
 				if len(booking.Participants) > 0 {
-					message += fmt.Sprintf("     👥 %s\n", strings.Join(booking.Participants, ", "))
+					message += fmt.Sprintf("     👥 %s\n", bot.EscapeMarkdown(strings.Join(booking.Participants, ", ")))
 				}
 			}
 		}
@@ -133,23 +186,107 @@ func (s *BookingService) FormatTimetableMessage(schedules []model.RoomSchedule)
 	return message
 }
 
-// FormatUserBookings formats a user's bookings into a message
-func (s *BookingService) FormatUserBookings(bookings []model.Booking) string {
+// BookingsPerPage keeps a list of bookings, such as a long series, within
+// Telegram's 4096-character message limit
+const BookingsPerPage = 8
+
+// BookingPage returns the bookings on page, counted from 0 and clamped to the
+// pages there are, together with the clamped page and the number of pages
+func BookingPage(bookings []model.Booking, page int) ([]model.Booking, int, int) {
+	pages := (len(bookings) + BookingsPerPage - 1) / BookingsPerPage
+	if pages == 0 {
+		return nil, 0, 1
+	}
+	page = max(0, min(page, pages-1))
+
+	start := page * BookingsPerPage
+	end := min(start+BookingsPerPage, len(bookings))
+	return bookings[start:end], page, pages
+}
+
+// FormatUserBookings formats one page of a user's bookings into a message
+func (s *BookingService) FormatUserBookings(bookings []model.Booking, page int) string {
 	if len(bookings) == 0 {
-		return "You have no active bookings."
+		return "You have no active bookings\\."
 	}
 
+	shown, page, pages := BookingPage(bookings, page)
+
 	message := "*Your Bookings:*\n\n"
-	for i, booking := range bookings {
-		message += fmt.Sprintf("%d. 🏢 %s\n", i+1, booking.RoomName)
-		message += fmt.Sprintf("   📅 %s\n", booking.Date.Format("02 Jan 2006"))
-		message += fmt.Sprintf("   ⏰ %s - %s\n", booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
-		message += fmt.Sprintf("   📝 %s\n", booking.Topic)
-		if len(booking.Participants) > 0 {
-			message += fmt.Sprintf("   👥 %s\n", strings.Join(booking.Participants, ", "))
+	for i, booking := range shown {
+		message += fmt.Sprintf("%d\\. 🏢 %s\n", page*BookingsPerPage+i+1, bot.EscapeMarkdown(booking.RoomName))
+		message += formatBookingDetails(booking)
+		if booking.SeriesID != nil {
+			message += fmt.Sprintf("   🔁 Series: `%d`\n", *booking.SeriesID)
 		}
 		message += fmt.Sprintf("   🔖 ID: `%d`\n\n", booking.BookingID)
 	}
 
+	if pages > 1 {
+		message += fmt.Sprintf("Page %d of %d", page+1, pages)
+	}
+
+	return message
+}
+
+// FormatBookingSummary shows a booking that has not been saved yet, laid out like FormatUserBookings
+func (s *BookingService) FormatBookingSummary(booking model.Booking) string {
+	message := "*Please confirm your booking:*\n\n"
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(booking.RoomName))
+	message += formatBookingDetails(booking)
+	return message
+}
+
+// FormatRescheduleSummary shows where an existing booking is about to be moved
+func (s *BookingService) FormatRescheduleSummary(booking model.Booking) string {
+	message := "*Please confirm the new time:*\n\n"
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(booking.RoomName))
+	message += formatBookingDetails(booking)
+	message += fmt.Sprintf("   🔖 ID: `%d`\n", booking.BookingID)
+	return message
+}
+
+// FormatReminder tells someone a booking starts in leadMinutes
+func (s *BookingService) FormatReminder(booking model.Booking, leadMinutes int) string {
+	message := fmt.Sprintf("🔔 *Reminder:* your meeting starts in %d minutes\n\n", leadMinutes)
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(booking.RoomName))
+	message += formatBookingDetails(booking)
+	message += fmt.Sprintf("   👤 By: %s\n", bot.EscapeMarkdown(booking.FullName))
+	return message
+}
+
+// Participant notice kinds for FormatParticipantNotice
+const (
+	NoticeAdded     = "added"
+	NoticeMoved     = "moved"
+	NoticeCancelled = "cancelled"
+)
+
+// FormatParticipantNotice tells a participant they were added to a booking,
+// or that a booking they are in was moved or cancelled
+func (s *BookingService) FormatParticipantNotice(kind string, booking model.Booking) string {
+	var message string
+	switch kind {
+	case NoticeMoved:
+		message = "🕑 *A meeting you are in has moved*\n\n"
+	case NoticeCancelled:
+		message = "❌ *A meeting you are in was cancelled*\n\n"
+	default:
+		message = "📨 *You have been added to a meeting*\n\n"
+	}
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(booking.RoomName))
+	message += formatBookingDetails(booking)
+	message += fmt.Sprintf("   👤 By: %s\n", bot.EscapeMarkdown(booking.FullName))
+	return message
+}
+
+// formatBookingDetails lists the date, time range, topic and participants of a booking
+func formatBookingDetails(booking model.Booking) string {
+	message := fmt.Sprintf("   📅 %s\n", booking.Date.Format("02 Jan 2006"))
+	message += fmt.Sprintf("   ⏰ %s \\- %s\n", booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
+	message += fmt.Sprintf("   📝 %s\n", bot.EscapeMarkdown(booking.Topic))
+	if len(booking.Participants) > 0 {
+		message += fmt.Sprintf("   👥 %s\n", bot.EscapeMarkdown(strings.Join(booking.Participants, ", ")))
+	}
 	return message
 }
@@ -0,0 +1,189 @@
+// internal/service/booking_test.go
+
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/config"
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/state"
+)
+
+// Monday 21 October 2030; the tests book this day and the Saturday after it
+var (
+	monday   = time.Date(2030, 10, 21, 0, 0, 0, 0, time.UTC)
+	saturday = time.Date(2030, 10, 26, 0, 0, 0, 0, time.UTC)
+)
+
+func testBookingConfig() config.BookingConfig {
+	return config.BookingConfig{
+		WorkdayStart: "09:00",
+		WorkdayEnd:   "12:00",
+		SlotDuration: 60,
+		HoldDuration: 10,
+		WeekdayHours: map[string]config.OperatingHours{
+			"saturday": {Closed: true},
+		},
+	}
+}
+
+// at returns date at hour:minute
+func at(date time.Time, hour, minute int) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+}
+
+// slotState names what the timetable shows for a slot
+func slotState(slot model.TimeSlot) string {
+	switch {
+	case slot.Booking != nil:
+		return "booked"
+	case slot.IsPast:
+		return "past"
+	case slot.IsHeld:
+		return "held"
+	case slot.IsFree:
+		return "free"
+	}
+	return "unknown"
+}
+
+func TestGenerateTimetableForDate(t *testing.T) {
+	type span struct{ start, end string }
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		now      time.Time
+		bookings []span
+		hold     *span
+		want     []string
+	}{
+		{
+			name: "all free",
+			date: monday,
+			now:  at(monday, 8, 0),
+			want: []string{"free", "free", "free"},
+		},
+		{
+			name:     "booking covers several slots",
+			date:     monday,
+			now:      at(monday, 8, 0),
+			bookings: []span{{"09:00", "11:00"}},
+			want:     []string{"booked", "booked", "free"},
+		},
+		{
+			name:     "booking overlaps part of a slot",
+			date:     monday,
+			now:      at(monday, 8, 0),
+			bookings: []span{{"10:30", "11:30"}},
+			want:     []string{"free", "booked", "booked"},
+		},
+		{
+			name: "held by someone mid-booking",
+			date: monday,
+			now:  at(monday, 8, 0),
+			hold: &span{"10:00", "12:00"},
+			want: []string{"free", "held", "held"},
+		},
+		{
+			name:     "started slots are past",
+			date:     monday,
+			now:      at(monday, 10, 30),
+			bookings: []span{{"09:00", "10:00"}},
+			want:     []string{"booked", "past", "free"},
+		},
+		{
+			name: "closed weekday",
+			date: saturday,
+			now:  at(monday, 8, 0),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := func() time.Time { return tt.now }
+
+			store := db.NewMemory(now)
+			if err := store.SeedRooms(ctx, []model.Room{{RoomName: "Room A", Capacity: 10}}); err != nil {
+				t.Fatal(err)
+			}
+			user, err := store.CreateOrGetUser(ctx, 1, "organizer", "Organizer")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range tt.bookings {
+				start, _ := time.Parse("15:04", s.start)
+				end, _ := time.Parse("15:04", s.end)
+				booking := model.Booking{
+					RoomID:    1,
+					UserID:    user.UserID,
+					Topic:     "Planning",
+					Date:      tt.date,
+					StartTime: at(tt.date, start.Hour(), start.Minute()),
+					EndTime:   at(tt.date, end.Hour(), end.Minute()),
+				}
+				if err := store.CreateBooking(ctx, &booking, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			holds := state.NewHoldManager(now)
+			if tt.hold != nil {
+				held, err := holds.Hold(ctx, 1, tt.date, tt.hold.start, tt.hold.end, 2, time.Hour)
+				if err != nil || !held {
+					t.Fatalf("Hold() = %v, %v", held, err)
+				}
+			}
+
+			s := NewBookingService(store, store, holds, testBookingConfig(), now)
+			schedules, err := s.GenerateTimetableForDate(ctx, tt.date)
+			if err != nil {
+				t.Fatalf("GenerateTimetableForDate() error = %v", err)
+			}
+			if len(schedules) != 1 {
+				t.Fatalf("got %d schedules, want 1", len(schedules))
+			}
+
+			var got []string
+			for _, slot := range schedules[0].TimeSlots {
+				got = append(got, slotState(slot))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("slots = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBookingSummary(t *testing.T) {
+	booking := model.Booking{
+		RoomName:     "Room A",
+		Topic:        "Q4 plan (draft)!",
+		Date:         monday,
+		StartTime:    at(monday, 9, 0),
+		EndTime:      at(monday, 10, 30),
+		Participants: []string{"alice_smith", "Bob"},
+	}
+
+	got := (&BookingService{}).FormatBookingSummary(booking)
+	// User text is escaped so the summary always renders as MarkdownV2
+	for _, want := range []string{
+		"🏢 Room A\n",
+		"⏰ 09:00 \\- 10:30\n",
+		"📝 Q4 plan \\(draft\\)\\!\n",
+		"👥 alice\\_smith, Bob\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatBookingSummary() = %q, want it to contain %q", got, want)
+		}
+	}
+}
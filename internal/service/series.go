@@ -0,0 +1,156 @@
+// internal/service/series.go
+
+package service
+
+import (
+	"fmt"
+	"strings"
+	"telegrarmchatbot/internal/model"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// MaxSeriesOccurrences caps how many bookings a single series creates
+const MaxSeriesOccurrences = 52
+
+// MaxSeriesSpan is how far past its first date a series may run
+const MaxSeriesSpan = 365 * 24 * time.Hour
+
+// SkippedOccurrence is a date of a series that was not booked, and why
+type SkippedOccurrence struct {
+	Date   time.Time
+	Reason string
+}
+
+// SeriesDates lists the dates of a series, starting with StartDate. It stops at
+// UntilDate, after Count dates, or at the MaxSeriesOccurrences/MaxSeriesSpan limits.
+// Monthly series skip months that don't have the start day (e.g. the 31st).
+func SeriesDates(series model.BookingSeries) []time.Time {
+	start := series.StartDate
+	last := start.Add(MaxSeriesSpan)
+	if !series.UntilDate.IsZero() && series.UntilDate.Before(last) {
+		last = series.UntilDate
+	}
+
+	limit := MaxSeriesOccurrences
+	if series.Count > 0 && series.Count < limit {
+		limit = series.Count
+	}
+
+	var dates []time.Time
+	for day := start; !day.After(last) && len(dates) < limit; day = day.AddDate(0, 0, 1) {
+		switch series.Frequency {
+		case model.FrequencyDaily:
+		case model.FrequencyWeekly:
+			if !containsWeekday(series.Weekdays, day.Weekday()) {
+				continue
+			}
+		case model.FrequencyMonthly:
+			if day.Day() != start.Day() {
+				continue
+			}
+		default:
+			return nil
+		}
+		dates = append(dates, day)
+	}
+	return dates
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, w := range weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanSeries expands a series into the bookings to create. Dates on which the
+// room is closed at the series' time are returned as skipped instead.
+func (s *BookingService) PlanSeries(series model.BookingSeries, roomName string) ([]model.Booking, []SkippedOccurrence) {
+	startStr := series.StartTime.Format("15:04")
+	endStr := series.EndTime.Format("15:04")
+
+	var occurrences []model.Booking
+	var skipped []SkippedOccurrence
+	for _, date := range SeriesDates(series) {
+		if !s.withinHours(roomName, date, startStr, endStr) {
+			skipped = append(skipped, SkippedOccurrence{Date: date, Reason: "room closed"})
+			continue
+		}
+
+		occurrences = append(occurrences, model.Booking{
+			RoomID:    series.RoomID,
+			UserID:    series.UserID,
+			Topic:     series.Topic,
+			Date:      date,
+			StartTime: time.Date(date.Year(), date.Month(), date.Day(), series.StartTime.Hour(), series.StartTime.Minute(), 0, 0, date.Location()),
+			EndTime:   time.Date(date.Year(), date.Month(), date.Day(), series.EndTime.Hour(), series.EndTime.Minute(), 0, 0, date.Location()),
+			RoomName:  roomName,
+		})
+	}
+	return occurrences, skipped
+}
+
+// withinHours reports whether startStr-endStr lines up with the room's slots on date
+func (s *BookingService) withinHours(roomName string, date time.Time, startStr, endStr string) bool {
+	startOK, endOK := false, false
+	for _, slot := range s.Config.GenerateTimeSlots(roomName, date) {
+		startOK = startOK || slot.Start == startStr
+		endOK = endOK || slot.End == endStr
+	}
+	return startOK && endOK
+}
+
+// FormatRecurrence describes how often a series repeats, e.g. "Weekly on Mon, Wed, 10 times"
+func (s *BookingService) FormatRecurrence(series model.BookingSeries) string {
+	var message string
+	switch series.Frequency {
+	case model.FrequencyDaily:
+		message = "Daily"
+	case model.FrequencyWeekly:
+		var days []string
+		for _, weekday := range series.Weekdays {
+			days = append(days, weekday.String()[:3])
+		}
+		message = "Weekly on " + strings.Join(days, ", ")
+	case model.FrequencyMonthly:
+		message = fmt.Sprintf("Monthly on day %d", series.StartDate.Day())
+	default:
+		return "Does not repeat"
+	}
+
+	if !series.UntilDate.IsZero() {
+		message += " until " + series.UntilDate.Format("02 Jan 2006")
+	} else if series.Count > 0 {
+		message += fmt.Sprintf(", %d times", series.Count)
+	}
+	return message
+}
+
+// FormatSeriesResult reports which occurrences of a new series were booked and which were not
+func (s *BookingService) FormatSeriesResult(series model.BookingSeries, roomName string, booked []model.Booking, skipped []SkippedOccurrence) string {
+	message := "✅ *Recurring booking created\\!*\n\n"
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(roomName))
+	message += fmt.Sprintf("⏰ %s \\- %s\n", series.StartTime.Format("15:04"), series.EndTime.Format("15:04"))
+	message += fmt.Sprintf("📝 %s\n", bot.EscapeMarkdown(series.Topic))
+	message += fmt.Sprintf("🔁 %s\n", bot.EscapeMarkdown(s.FormatRecurrence(series)))
+	message += fmt.Sprintf("🔖 Series: `%d`\n\n", series.SeriesID)
+
+	message += fmt.Sprintf("*Booked \\(%d\\):*\n", len(booked))
+	for _, booking := range booked {
+		message += fmt.Sprintf("  ✅ %s  ID: `%d`\n", booking.Date.Format("Mon 02 Jan 2006"), booking.BookingID)
+	}
+
+	if len(skipped) > 0 {
+		message += fmt.Sprintf("\n*Not booked \\(%d\\):*\n", len(skipped))
+		for _, occurrence := range skipped {
+			message += fmt.Sprintf("  ❌ %s \\- %s\n", occurrence.Date.Format("Mon 02 Jan 2006"), bot.EscapeMarkdown(occurrence.Reason))
+		}
+	}
+
+	message += "\nEach date can be changed with /reschedule or /cancel\\."
+	return message
+}
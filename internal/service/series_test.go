@@ -0,0 +1,172 @@
+// internal/service/series_test.go
+
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"telegrarmchatbot/internal/model"
+)
+
+// days formats dates as "2006-01-02" for comparison
+func days(dates []time.Time) []string {
+	var formatted []string
+	for _, date := range dates {
+		formatted = append(formatted, date.Format("2006-01-02"))
+	}
+	return formatted
+}
+
+func TestSeriesDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		series  model.BookingSeries
+		want    []string
+		wantLen int // checked instead of want when set
+	}{
+		{
+			name:   "daily count",
+			series: model.BookingSeries{Frequency: model.FrequencyDaily, StartDate: monday, Count: 3},
+			want:   []string{"2030-10-21", "2030-10-22", "2030-10-23"},
+		},
+		{
+			name:   "daily until",
+			series: model.BookingSeries{Frequency: model.FrequencyDaily, StartDate: monday, UntilDate: monday.AddDate(0, 0, 2)},
+			want:   []string{"2030-10-21", "2030-10-22", "2030-10-23"},
+		},
+		{
+			name: "weekly on two days",
+			series: model.BookingSeries{
+				Frequency: model.FrequencyWeekly,
+				Weekdays:  []time.Weekday{time.Monday, time.Wednesday},
+				StartDate: monday,
+				Count:     4,
+			},
+			want: []string{"2030-10-21", "2030-10-23", "2030-10-28", "2030-10-30"},
+		},
+		{
+			name: "weekly starting off its weekdays",
+			series: model.BookingSeries{
+				Frequency: model.FrequencyWeekly,
+				Weekdays:  []time.Weekday{time.Friday},
+				StartDate: monday,
+				Count:     2,
+			},
+			want: []string{"2030-10-25", "2030-11-01"},
+		},
+		{
+			name: "monthly skips short months",
+			series: model.BookingSeries{
+				Frequency: model.FrequencyMonthly,
+				StartDate: time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC),
+				Count:     3,
+			},
+			want: []string{"2027-01-31", "2027-03-31", "2027-05-31"},
+		},
+		{
+			name:    "capped at the occurrence limit",
+			series:  model.BookingSeries{Frequency: model.FrequencyDaily, StartDate: monday, Count: 100},
+			wantLen: MaxSeriesOccurrences,
+		},
+		{
+			name: "capped at the span limit",
+			series: model.BookingSeries{
+				Frequency: model.FrequencyMonthly,
+				StartDate: monday,
+				UntilDate: monday.AddDate(3, 0, 0),
+			},
+			wantLen: 13, // 21 Oct 2030 to 21 Oct 2031, both included
+		},
+		{
+			name:   "unknown frequency",
+			series: model.BookingSeries{Frequency: "YEARLY", StartDate: monday, Count: 3},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SeriesDates(tt.series)
+			if tt.wantLen != 0 {
+				if len(got) != tt.wantLen {
+					t.Errorf("got %d dates, want %d", len(got), tt.wantLen)
+				}
+				return
+			}
+			if gotDays := days(got); !slices.Equal(gotDays, tt.want) {
+				t.Errorf("SeriesDates() = %v, want %v", gotDays, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanSeries(t *testing.T) {
+	tests := []struct {
+		name        string
+		start, end  time.Time
+		series      model.BookingSeries
+		wantBooked  []string
+		wantSkipped []string
+	}{
+		{
+			name:        "closed weekday is skipped",
+			start:       at(monday, 9, 0),
+			end:         at(monday, 10, 0),
+			series:      model.BookingSeries{Frequency: model.FrequencyDaily, Count: 6},
+			wantBooked:  []string{"2030-10-21", "2030-10-22", "2030-10-23", "2030-10-24", "2030-10-25"},
+			wantSkipped: []string{"2030-10-26"},
+		},
+		{
+			name:        "outside opening hours",
+			start:       at(monday, 11, 0),
+			end:         at(monday, 13, 0),
+			series:      model.BookingSeries{Frequency: model.FrequencyDaily, Count: 2},
+			wantSkipped: []string{"2030-10-21", "2030-10-22"},
+		},
+		{
+			name:       "several slots",
+			start:      at(monday, 9, 0),
+			end:        at(monday, 12, 0),
+			series:     model.BookingSeries{Frequency: model.FrequencyWeekly, Weekdays: []time.Weekday{time.Monday}, Count: 2},
+			wantBooked: []string{"2030-10-21", "2030-10-28"},
+		},
+	}
+
+	s := &BookingService{Config: testBookingConfig()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := tt.series
+			series.RoomID = 1
+			series.StartDate = monday
+			series.StartTime = tt.start
+			series.EndTime = tt.end
+
+			occurrences, skipped := s.PlanSeries(series, "Room A")
+
+			var booked []time.Time
+			for _, occurrence := range occurrences {
+				booked = append(booked, occurrence.Date)
+				if occurrence.StartTime.Format("15:04") != tt.start.Format("15:04") || !sameDate(occurrence.StartTime, occurrence.Date) {
+					t.Errorf("occurrence on %s starts at %s", occurrence.Date.Format("2006-01-02"), occurrence.StartTime)
+				}
+			}
+			var skippedDates []time.Time
+			for _, occurrence := range skipped {
+				skippedDates = append(skippedDates, occurrence.Date)
+			}
+
+			if got := days(booked); !slices.Equal(got, tt.wantBooked) {
+				t.Errorf("booked = %v, want %v", got, tt.wantBooked)
+			}
+			if got := days(skippedDates); !slices.Equal(got, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", got, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
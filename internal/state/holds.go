@@ -0,0 +1,117 @@
+// internal/state/holds.go
+
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Holds reserves time ranges for users in the middle of booking and for open
+// waitlist offers, at most one per holder. HoldManager keeps them in process; db.PostgresHolds shares them
+// between replicas.
+//
+// Holds are advisory: they keep a range off the timetable and the end time
+// picker, but CreateBooking and UpdateBookingTime don't check them. Double
+// bookings are prevented by the bookings exclusion constraint alone.
+type Holds interface {
+	// Hold reserves startTime-endTime for userID until ttl passes, replacing any
+	// previous hold of theirs. It returns false if another user's hold overlaps.
+	Hold(ctx context.Context, roomID int, date time.Time, startTime, endTime string, userID int64, ttl time.Duration) (bool, error)
+	// HeldBy returns the user holding any part of startTime-endTime, if the hold has not expired
+	HeldBy(ctx context.Context, roomID int, date time.Time, startTime, endTime string) (int64, bool, error)
+	// Extend pushes the expiry of userID's hold to ttl from now, if they have one
+	Extend(ctx context.Context, userID int64, ttl time.Duration) error
+	// Release drops the hold owned by userID
+	Release(ctx context.Context, userID int64) error
+}
+
+// SlotHold reserves a time range in a room for one user while they finish the booking conversation
+type SlotHold struct {
+	RoomID    int
+	Date      string // "2006-01-02"
+	StartTime string // "15:04"
+	EndTime   string // "15:04"
+	ExpiresAt time.Time
+}
+
+// HoldManager keeps at most one hold per user in process, keyed by telegram ID
+type HoldManager struct {
+	holds map[int64]SlotHold
+	now   func() time.Time
+	mu    sync.Mutex
+}
+
+// NewHoldManager creates an empty manager that reads the time from now
+func NewHoldManager(now func() time.Time) *HoldManager {
+	return &HoldManager{
+		holds: make(map[int64]SlotHold),
+		now:   now,
+	}
+}
+
+func (h SlotHold) overlaps(roomID int, date, startTime, endTime string) bool {
+	return h.RoomID == roomID && h.Date == date && h.StartTime < endTime && h.EndTime > startTime
+}
+
+var _ Holds = (*HoldManager)(nil)
+
+func (hm *HoldManager) Hold(ctx context.Context, roomID int, date time.Time, startTime, endTime string, userID int64, ttl time.Duration) (bool, error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	now := hm.now()
+	day := date.Format("2006-01-02")
+	for holder, hold := range hm.holds {
+		if !now.Before(hold.ExpiresAt) {
+			delete(hm.holds, holder)
+			continue
+		}
+		if holder != userID && hold.overlaps(roomID, day, startTime, endTime) {
+			return false, nil
+		}
+	}
+
+	hm.holds[userID] = SlotHold{
+		RoomID:    roomID,
+		Date:      day,
+		StartTime: startTime,
+		EndTime:   endTime,
+		ExpiresAt: now.Add(ttl),
+	}
+	return true, nil
+}
+
+func (hm *HoldManager) HeldBy(ctx context.Context, roomID int, date time.Time, startTime, endTime string) (int64, bool, error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	now := hm.now()
+	day := date.Format("2006-01-02")
+	for holder, hold := range hm.holds {
+		if now.Before(hold.ExpiresAt) && hold.overlaps(roomID, day, startTime, endTime) {
+			return holder, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (hm *HoldManager) Extend(ctx context.Context, userID int64, ttl time.Duration) error {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	now := hm.now()
+	if hold, ok := hm.holds[userID]; ok && now.Before(hold.ExpiresAt) {
+		hold.ExpiresAt = now.Add(ttl)
+		hm.holds[userID] = hold
+	}
+	return nil
+}
+
+func (hm *HoldManager) Release(ctx context.Context, userID int64) error {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	delete(hm.holds, userID)
+	return nil
+}
@@ -0,0 +1,96 @@
+// internal/state/holds_test.go
+
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHoldManagerHold(t *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration // of user 1's hold on room 1, 10:00-11:00
+		roomID   int
+		date     time.Time
+		start    string
+		end      string
+		userID   int64
+		wantHeld bool
+	}{
+		{"overlapping", time.Hour, 1, day, "10:30", "11:30", 2, false},
+		{"containing", time.Hour, 1, day, "09:00", "12:00", 2, false},
+		{"right after", time.Hour, 1, day, "11:00", "12:00", 2, true},
+		{"other room", time.Hour, 2, day, "10:00", "11:00", 2, true},
+		{"other day", time.Hour, 1, day.AddDate(0, 0, 1), "10:00", "11:00", 2, true},
+		{"same user moves their hold", time.Hour, 1, day, "10:30", "11:30", 1, true},
+		{"expired", 0, 1, day, "10:00", "11:00", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			hm := NewHoldManager(func() time.Time { return day })
+			if held, err := hm.Hold(ctx, 1, day, "10:00", "11:00", 1, tt.ttl); err != nil || !held {
+				t.Fatalf("first Hold() = %v, %v", held, err)
+			}
+
+			held, err := hm.Hold(ctx, tt.roomID, tt.date, tt.start, tt.end, tt.userID, time.Hour)
+			if err != nil || held != tt.wantHeld {
+				t.Fatalf("Hold() = %v, %v, want %v", held, err, tt.wantHeld)
+			}
+			if !tt.wantHeld {
+				return
+			}
+			if holder, held, err := hm.HeldBy(ctx, tt.roomID, tt.date, tt.start, tt.end); err != nil || !held || holder != tt.userID {
+				t.Errorf("HeldBy() = %d, %v, %v, want %d, true", holder, held, err, tt.userID)
+			}
+		})
+	}
+}
+
+func TestHoldManagerRelease(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	hm := NewHoldManager(func() time.Time { return day })
+	hm.Hold(ctx, 1, day, "10:00", "11:00", 1, time.Hour)
+
+	if err := hm.Release(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, held, _ := hm.HeldBy(ctx, 1, day, "10:00", "11:00"); held {
+		t.Error("slot still held after Release")
+	}
+	if held, _ := hm.Hold(ctx, 1, day, "10:00", "11:00", 2, time.Hour); !held {
+		t.Error("released slot can't be held by someone else")
+	}
+}
+
+func TestHoldManagerExtend(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	now := day
+	hm := NewHoldManager(func() time.Time { return now })
+	hm.Hold(ctx, 1, day, "10:00", "11:00", 1, 10*time.Minute)
+
+	// Each answer keeps the hold 10 more minutes from then
+	for i := 0; i < 3; i++ {
+		now = now.Add(8 * time.Minute)
+		if err := hm.Extend(ctx, 1, 10*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, held, _ := hm.HeldBy(ctx, 1, day, "10:00", "11:00"); !held {
+		t.Fatal("hold expired although it was extended")
+	}
+
+	// An expired hold is not brought back
+	now = now.Add(11 * time.Minute)
+	hm.Extend(ctx, 1, 10*time.Minute)
+	if _, held, _ := hm.HeldBy(ctx, 1, day, "10:00", "11:00"); held {
+		t.Error("Extend() revived an expired hold")
+	}
+}
@@ -1,55 +1,130 @@
-// internal/state/session.go - NEW FILE
+// internal/state/session.go
 
 package state
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
+// Booking conversation steps, in the order the user walks through them.
+const (
+	StepSelectDate        = "select_date"
+	StepSelectRoom        = "select_room"
+	StepSelectTime        = "select_time"
+	StepSelectEndTime     = "select_end_time"
+	StepEnterTopic        = "enter_topic"
+	StepEnterParticipants = "enter_participants"
+	StepReview            = "review"
+	StepSelectRepeat      = "select_repeat"    // optional, opened from the review step
+	StepEnterRepeatEnd    = "enter_repeat_end" // optional, opened from the review step
+)
+
 type BookingSession struct {
-	UserID       int64
-	Step         string // "select_room", "select_time", "enter_topic", "enter_participants"
-	RoomID       int
-	RoomName     string
-	Date         time.Time
-	StartTime    string
-	EndTime      string
-	Topic        string
-	Participants []string
-}
-
-type SessionManager struct {
-	sessions map[int64]*BookingSession
+	UserID           int64          `json:"user_id"`
+	Step             string         `json:"step"` // "select_date", "select_room", "select_time", "select_end_time", "enter_topic", "enter_participants", "review"
+	RoomID           int            `json:"room_id"`
+	RoomName         string         `json:"room_name"`
+	Date             time.Time      `json:"date"`
+	StartTime        string         `json:"start_time"`
+	EndTime          string         `json:"end_time"`
+	Topic            string         `json:"topic"`
+	Participants     []string       `json:"participants"`
+	ParticipantUsers map[string]int `json:"participant_users"` // users.user_id of participants who are Telegram users, by name
+	Editing          bool           `json:"editing"`           // return to the review step once the edited field is set
+	BookingID        int            `json:"booking_id"`        // booking being rescheduled, 0 for a new booking
+	Repeat           string         `json:"repeat"`            // model.Frequency* for a recurring booking, empty for a single one
+	RepeatDays       []time.Weekday `json:"repeat_days"`       // weekly series only
+	RepeatCount      int            `json:"repeat_count"`      // series length, unless RepeatUntil is set
+	RepeatUntil      time.Time      `json:"repeat_until"`
+	ChatID           int64          `json:"chat_id"`       // where to tell the user the session expired
+	LastActivity     time.Time      `json:"last_activity"` // set on every save
+}
+
+// Expired reports whether the session has been idle for longer than ttl
+func (s *BookingSession) Expired(now time.Time, ttl time.Duration) bool {
+	return now.Sub(s.LastActivity) > ttl
+}
+
+// SessionManager stores booking conversations keyed by telegram ID.
+// GetSession returns nil, nil when the user has no session.
+type SessionManager interface {
+	GetSession(ctx context.Context, userID int64) (*BookingSession, error)
+	SetSession(ctx context.Context, userID int64, session *BookingSession) error
+	ClearSession(ctx context.Context, userID int64) error
+	// RemoveExpired deletes sessions idle since before cutoff and returns them,
+	// leaving their holds to the caller. Each session is returned by exactly one call.
+	RemoveExpired(ctx context.Context, cutoff time.Time) ([]BookingSession, error)
+}
+
+// StartBooking replaces any existing session of the user with a fresh one
+func StartBooking(ctx context.Context, sm SessionManager, userID, chatID int64, now time.Time) error {
+	if err := sm.ClearSession(ctx, userID); err != nil {
+		return err
+	}
+	return sm.SetSession(ctx, userID, &BookingSession{
+		UserID:       userID,
+		Step:         StepSelectDate,
+		ChatID:       chatID,
+		LastActivity: now,
+	})
+}
+
+// MemorySessionManager keeps sessions in process; they are lost on restart
+type MemorySessionManager struct {
+	sessions map[int64]BookingSession
+	holds    Holds
 	mu       sync.RWMutex
 }
 
-var Manager = &SessionManager{
-	sessions: make(map[int64]*BookingSession),
+// NewMemorySessionManager creates an empty manager; holds, if set, are released with their session
+func NewMemorySessionManager(holds Holds) *MemorySessionManager {
+	return &MemorySessionManager{
+		sessions: make(map[int64]BookingSession),
+		holds:    holds,
+	}
 }
 
-func (sm *SessionManager) GetSession(userID int64) *BookingSession {
+// GetSession returns a copy, so changes only take effect through SetSession like other backends
+func (sm *MemorySessionManager) GetSession(ctx context.Context, userID int64) (*BookingSession, error) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
-	return sm.sessions[userID]
+	session, exists := sm.sessions[userID]
+	if !exists {
+		return nil, nil
+	}
+	return &session, nil
 }
 
-func (sm *SessionManager) SetSession(userID int64, session *BookingSession) {
+func (sm *MemorySessionManager) SetSession(ctx context.Context, userID int64, session *BookingSession) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.sessions[userID] = session
+	sm.sessions[userID] = *session
+	return nil
 }
 
-func (sm *SessionManager) ClearSession(userID int64) {
+// ClearSession ends the user's booking conversation and releases their slot hold
+func (sm *MemorySessionManager) ClearSession(ctx context.Context, userID int64) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	delete(sm.sessions, userID)
+	if sm.holds != nil {
+		return sm.holds.Release(ctx, userID)
+	}
+	return nil
 }
 
-func (sm *SessionManager) StartBooking(userID int64) {
-	sm.SetSession(userID, &BookingSession{
-		UserID: userID,
-		Step:   "select_room",
-		Date:   time.Now(),
-	})
-}
\ No newline at end of file
+func (sm *MemorySessionManager) RemoveExpired(ctx context.Context, cutoff time.Time) ([]BookingSession, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var expired []BookingSession
+	for userID, session := range sm.sessions {
+		if session.LastActivity.Before(cutoff) {
+			expired = append(expired, session)
+			delete(sm.sessions, userID)
+		}
+	}
+	return expired, nil
+}
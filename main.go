@@ -1,21 +1,18 @@
-// main.go - FINAL VERSION
+// main.go
 
 package main
 
 import (
 	"context"
-	"database/sql"
-	
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
-	"path/filepath"
-	
-	"strings"
-	
+	"time"
+
 	"telegrarmchatbot/db"
-	
-	"telegrarmchatbot/internal/service"
+	"telegrarmchatbot/internal/config"
+	"telegrarmchatbot/internal/model"
 	"telegrarmchatbot/internal/state"
 
 	"github.com/go-telegram/bot"
@@ -23,94 +20,108 @@ import (
 	_ "github.com/lib/pq"
 )
 
-var (
-	database       *sql.DB
-	bookingService *service.BookingService
-)
-
 func main() {
-	// Read token
-	wd, err := os.Getwd()
+	// Load configuration
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("unable to get working directory: %v", err)
+		log.Fatalf("unable to load config: %v", err)
 	}
 
-	filePath := filepath.Join(wd, "Token.txt")
-	contentBytes, err := os.ReadFile(filePath)
-	if err != nil {
-		log.Fatalf("unable to read file: %v", err)
-	}
-	token := strings.TrimSpace(string(contentBytes))
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
 
 	// Connect to database
-	database, err = db.Connect()
+	database, err := db.Connect(ctx, cfg.Database.URL)
 	if err != nil {
 		log.Fatalf("unable to connect to database: %v", err)
 	}
 	defer database.Close()
 
-	// Initialize tables
-	if err := db.InitTables(database); err != nil {
-		log.Fatalf("unable to create tables: %v", err)
+	// `main migrate up|down|status` manages the schema without starting the bot
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrateCommand(ctx, database, os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
+	if err := cfg.ValidateTelegram(); err != nil {
+		log.Fatalf("invalid config: %v", err)
 	}
 
+	// Apply pending schema migrations
+	if err := db.MigrateUp(ctx, database); err != nil {
+		log.Fatalf("unable to migrate database: %v", err)
+	}
+
+	store := db.NewPostgres(database, cfg.Database.QueryTimeoutDuration())
+
 	// Seed rooms
-	if err := db.SeedRooms(database); err != nil {
+	var rooms []model.Room
+	for _, room := range cfg.Rooms {
+		rooms = append(rooms, model.Room{RoomName: room.Name, Capacity: room.Capacity})
+	}
+	if err := store.SeedRooms(ctx, rooms); err != nil {
 		log.Fatalf("unable to seed rooms: %v", err)
 	}
 
-	// Initialize booking service
-	bookingService = service.NewBookingService(database)
-
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer cancel()
+	app, err := NewApp(cfg, store, log.Default(), time.Now)
+	if err != nil {
+		log.Fatalf("unable to create app: %v", err)
+	}
 
 	opts := []bot.Option{
-		bot.WithDefaultHandler(handler),
+		bot.WithDefaultHandler(app.handler),
 	}
 
-	b, err := bot.New(token, opts...)
+	b, err := bot.New(cfg.Telegram.Token, opts...)
 	if err != nil {
 		panic(err)
 	}
 
-	// Register handlers
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, startHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/help", bot.MatchTypeExact, helpHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/book", bot.MatchTypeExact, bookHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/cancel", bot.MatchTypeExact, cancelHandler)
+	app.registerHandlers(b)
+	go app.sweepSessions(ctx, b)
+	go app.sendReminders(ctx, b)
+	go app.watchCheckIns(ctx, b)
+	go app.watchWaitlist(ctx, b)
 
 	log.Println("Bot started successfully!")
 	b.Start(ctx)
 }
 
-func handler(ctx context.Context, b *bot.Bot, update *models.Update) {
+func (a *App) handler(ctx context.Context, b *bot.Bot, update *models.Update) {
 
 	if update.Message == nil || update.Message.From == nil {
 		return
 	}
 
+	// Free text while booking belongs to the current step
+	if a.textStepHandler(ctx, b, update.Message) {
+		return
+	}
+
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: update.Message.Chat.ID,
 		Text:   "Unknown command. Type /help for available commands.",
 	})
 }
 
-func startHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+func (a *App) startHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	// Create or get user
 	telegramID := update.Message.From.ID
 	username := update.Message.From.Username
 	fullName := update.Message.From.FirstName + " " + update.Message.From.LastName
 
-	_, err := db.CreateOrGetUser(database, telegramID, username, fullName)
+	_, err := a.store.CreateOrGetUser(ctx, telegramID, username, fullName)
 	if err != nil {
-		log.Printf("Error creating user: %v", err)
+		a.logger.Printf("Error creating user: %v", err)
 	}
 
 	welcomeText := `ສະບາຍດີ! Welcome to Room Booking Bot 🏢
 
 Available commands:
 /book - Book a meeting room
+/reschedule - Move your booking to another time
 /cancel - Cancel your booking
 /help - Show help message
 
@@ -122,29 +133,44 @@ Let's get started!`
 	})
 }
 
-func helpHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+func (a *App) helpHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	rooms, err := a.store.GetAllActiveRooms(ctx)
+	if err != nil {
+		a.logger.Printf("Error getting rooms: %v", err)
+	}
+
+	roomList := ""
+	for _, room := range rooms {
+		roomList += fmt.Sprintf("- %s (%d seats)\n", room.RoomName, room.Capacity)
+	}
+	if roomList == "" {
+		roomList = "- No rooms available\n"
+	}
 
 	helpText := `*Room Booking Bot Help* 🏢
 
 *Available Commands:*
-/book - Book a meeting room
-/cancel - Cancel your booking
-/help - Show this help message
+/book \- Book a meeting room
+/reschedule \- Move your booking to another time
+/cancel \- Cancel your booking
+/help \- Show this help message
 
 *How to book:*
-1. Type /book
-2. View available time slots
-3. Select a room and time
-4. Enter meeting details
-5. Confirm booking
+1\. Type /book
+2\. Pick a date from the calendar
+3\. View available time slots
+4\. Select a room and time
+5\. Enter meeting details
+6\. Review and press ✅ Confirm
 
-*Rooms Available:*
-- Room A
-- Room B
-- Room C
+Use ⬅ Back or ✏ Edit to change anything before confirming\.
+Press 🔁 Repeat on the review screen for a daily, weekly or monthly series\.
+Add participants as @username or share their contacts, and those who have used this bot get a message when the meeting is booked, moved or cancelled\.
 
+*Rooms Available:*
+` + bot.EscapeMarkdown(roomList) + `
 *Operating Hours:*
-09:00 - 17:00 (1-hour slots)`
+` + bot.EscapeMarkdown(fmt.Sprintf("%s - %s (%d-minute slots)", a.config.Booking.WorkdayStart, a.config.Booking.WorkdayEnd, a.config.Booking.SlotDuration))
 
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:    update.Message.Chat.ID,
@@ -153,79 +179,55 @@ func helpHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	})
 }
 
-func bookHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	// Generate today's timetable
-	schedules, err := bookingService.GenerateTodayTimetable()
-	if err != nil {
+func (a *App) bookHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	// Start booking session
+	userID := update.Message.From.ID
+	if err := state.StartBooking(ctx, a.sessions, userID, update.Message.Chat.ID, a.now()); err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
-			Text:   "Sorry, unable to retrieve schedule. Please try again later.",
+			Text:   "Sorry, unable to start booking. Please try again later.",
 		})
-		log.Printf("Error getting timetable: %v", err)
+		a.logger.Printf("Error starting booking session: %v", err)
 		return
 	}
 
-	// Format and send schedule
-	message := bookingService.FormatTimetableMessage(schedules)
-	b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:    update.Message.Chat.ID,
-		Text:      message,
-		ParseMode: models.ParseModeMarkdown,
-	})
-
-	// Start booking session
-	userID := update.Message.From.ID
-	state.Manager.StartBooking(userID)
-
-	// Show room selection buttons
-	keyboard := &models.InlineKeyboardMarkup{
-		InlineKeyboard: [][]models.InlineKeyboardButton{
-			{
-				{Text: "🏢 Room A", CallbackData: "room | Room A"},
-				{Text: "🏢 Room B", CallbackData: "room | Room B"},
-				{Text: "🏢 Room C", CallbackData: "room | Room C"},
-			},
-		},
-	}
-
+	// Ask for the date first; the timetable follows once it is chosen
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:      update.Message.Chat.ID,
-		Text:        "Please select a room:",
-		ReplyMarkup: keyboard,
+		Text:        "📅 Please select a date:",
+		ReplyMarkup: calendarKeyboard(a.now(), a.now()),
 	})
 }
 
-
-
-func cancelHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+func (a *App) cancelHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	// Get user
 	telegramID := update.Message.From.ID
-	user, err := db.GetUserByTelegramID(database, telegramID)
+	user, err := a.store.GetUserByTelegramID(ctx, telegramID)
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
 			Text:   "Error retrieving your information.",
 		})
-		log.Printf("Error getting user: %v", err)
+		a.logger.Printf("Error getting user: %v", err)
 		return
 	}
 	// Get user's bookings
-	bookings, err := db.GetUserBookings(database, user.UserID)
+	bookings, err := a.store.GetUserBookings(ctx, user.UserID)
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
 			Text:   "Error retrieving your bookings.",
 		})
-		log.Printf("Error getting bookings: %v", err)
+		a.logger.Printf("Error getting bookings: %v", err)
 		return
 	}
 
-	// Format and send message
-	message := bookingService.FormatUserBookings(bookings)
+	// Format and send message with one cancel button per booking
+	message := a.service.FormatUserBookings(bookings, 0)
 	b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:    update.Message.Chat.ID,
-		Text:      message,
-		ParseMode: models.ParseModeMarkdown,
+		ChatID:      update.Message.Chat.ID,
+		Text:        message,
+		ParseMode:   models.ParseModeMarkdown,
+		ReplyMarkup: cancelKeyboard(bookings, 0),
 	})
-
-} // TODO: Add inline keyboard for cancellation
+}
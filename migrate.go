@@ -0,0 +1,40 @@
+// migrate.go
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"telegrarmchatbot/db"
+)
+
+// migrateCommand handles `migrate up`, `migrate down` and `migrate status`
+func migrateCommand(ctx context.Context, database *sql.DB, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		return db.MigrateUp(ctx, database)
+	case "down":
+		return db.MigrateDown(ctx, database)
+	case "status":
+		migrations, err := db.MigrationStatus(ctx, database)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			applied := "pending"
+			if migration.AppliedAt != nil {
+				applied = "applied " + migration.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", migration.Version, migration.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up|down|status", args[0])
+	}
+}
@@ -0,0 +1,34 @@
+// notify.go
+
+package main
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// notifyParticipants sends message to the participants of a booking who are
+// Telegram users, except organizerID, who sees the outcome in their own chat.
+// Participants who never started the bot can't be messaged; that is only logged.
+func (a *App) notifyParticipants(ctx context.Context, b *bot.Bot, bookingID int, organizerID int64, message string) {
+	participants, err := a.store.GetParticipantTelegramIDs(ctx, bookingID)
+	if err != nil {
+		a.logger.Printf("Error getting participants of booking %d: %v", bookingID, err)
+		return
+	}
+
+	for _, telegramID := range participants {
+		if telegramID == organizerID {
+			continue
+		}
+		if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    telegramID,
+			Text:      message,
+			ParseMode: models.ParseModeMarkdown,
+		}); err != nil {
+			a.logger.Printf("Error notifying participant %d of booking %d: %v", telegramID, bookingID, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// reminders.go
+
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"telegrarmchatbot/internal/model"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// sendReminders messages the organizer and participants of upcoming bookings at
+// each configured lead time, checking every minute until ctx is done.
+func (a *App) sendReminders(ctx context.Context, b *bot.Bot) {
+	leads := slices.Clone(a.config.Reminders.LeadTimes)
+	if len(leads) == 0 {
+		return
+	}
+	slices.Sort(leads)
+	furthest := time.Duration(leads[len(leads)-1]) * time.Minute
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := a.now()
+		bookings, err := a.store.GetUpcomingBookings(ctx, now, now.Add(furthest))
+		if err != nil {
+			a.logger.Printf("Error getting upcoming bookings: %v", err)
+			continue
+		}
+
+		for _, booking := range bookings {
+			start := time.Date(booking.Date.Year(), booking.Date.Month(), booking.Date.Day(),
+				booking.StartTime.Hour(), booking.StartTime.Minute(), 0, 0, now.Location())
+
+			// Only the nearest lead time that has been reached is sent, so a booking
+			// made 3 minutes ahead gets the 5 minute reminder and not the 15
+			lead := -1
+			for _, minutes := range leads {
+				if start.Sub(now) <= time.Duration(minutes)*time.Minute {
+					lead = minutes
+					break
+				}
+			}
+			if lead < 0 {
+				continue
+			}
+
+			claimed, err := a.store.ClaimReminder(ctx, booking.BookingID, lead)
+			if err != nil {
+				a.logger.Printf("Error claiming reminder for booking %d: %v", booking.BookingID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			a.remind(ctx, b, booking, lead)
+		}
+	}
+}
+
+// remind sends one reminder to the booking's organizer and participants
+func (a *App) remind(ctx context.Context, b *bot.Bot, booking model.Booking, leadMinutes int) {
+	recipients := []int64{booking.TelegramID}
+
+	participants, err := a.store.GetParticipantTelegramIDs(ctx, booking.BookingID)
+	if err != nil {
+		a.logger.Printf("Error getting participants of booking %d: %v", booking.BookingID, err)
+	}
+	for _, telegramID := range participants {
+		if !slices.Contains(recipients, telegramID) {
+			recipients = append(recipients, telegramID)
+		}
+	}
+
+	message := a.service.FormatReminder(booking, leadMinutes)
+	for _, telegramID := range recipients {
+		if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    telegramID,
+			Text:      message,
+			ParseMode: models.ParseModeMarkdown,
+		}); err != nil {
+			a.logger.Printf("Error sending reminder for booking %d to %d: %v", booking.BookingID, telegramID, err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+// repeat.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/service"
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Series lengths offered as buttons; any other count or an end date can be typed
+var repeatCounts = []int{5, 10, 20}
+
+// sessionSeries describes the recurring booking a session asks for
+func sessionSeries(session *state.BookingSession, booking *model.Booking) model.BookingSeries {
+	return model.BookingSeries{
+		UserID:    booking.UserID,
+		RoomID:    booking.RoomID,
+		Topic:     booking.Topic,
+		Frequency: session.Repeat,
+		Weekdays:  session.RepeatDays,
+		StartDate: booking.Date,
+		UntilDate: session.RepeatUntil,
+		Count:     session.RepeatCount,
+		StartTime: booking.StartTime,
+		EndTime:   booking.EndTime,
+	}
+}
+
+// repeatKeyboard offers the frequencies, plus weekday toggles once weekly is chosen
+func repeatKeyboard(session *state.BookingSession) [][]models.InlineKeyboardButton {
+	label := func(text string, selected bool) string {
+		if selected {
+			return "✔ " + text
+		}
+		return text
+	}
+
+	rows := [][]models.InlineKeyboardButton{
+		{
+			{Text: label("Daily", session.Repeat == model.FrequencyDaily), CallbackData: callbackData("repeat", model.FrequencyDaily)},
+			{Text: label("Weekly", session.Repeat == model.FrequencyWeekly), CallbackData: callbackData("repeat", model.FrequencyWeekly)},
+			{Text: label("Monthly", session.Repeat == model.FrequencyMonthly), CallbackData: callbackData("repeat", model.FrequencyMonthly)},
+		},
+	}
+
+	if session.Repeat == model.FrequencyWeekly {
+		var days []models.InlineKeyboardButton
+		for i := 1; i <= 7; i++ {
+			weekday := time.Weekday(i % 7) // Monday first
+			days = append(days, models.InlineKeyboardButton{
+				Text:         label(weekday.String()[:2], slices.Contains(session.RepeatDays, weekday)),
+				CallbackData: callbackData("repeat_day", strconv.Itoa(int(weekday))),
+			})
+		}
+		rows = append(rows, days, []models.InlineKeyboardButton{
+			{Text: "Next ➡", CallbackData: callbackData("repeat_next", "")},
+		})
+	}
+
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: "🚫 Does not repeat", CallbackData: callbackData("repeat", "none")},
+	})
+	return rows
+}
+
+// repeatEndKeyboard offers the common series lengths
+func repeatEndKeyboard() [][]models.InlineKeyboardButton {
+	var row []models.InlineKeyboardButton
+	for _, count := range repeatCounts {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%d times", count),
+			CallbackData: callbackData("repeat_count", strconv.Itoa(count)),
+		})
+	}
+	return [][]models.InlineKeyboardButton{row}
+}
+
+// hasRepeatEnd reports whether the series length has been set, as a count or an end date
+func hasRepeatEnd(session *state.BookingSession) bool {
+	return session.RepeatCount > 0 || !session.RepeatUntil.IsZero()
+}
+
+// repeatCallback picks how often the booking repeats
+func (a *App) repeatCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepSelectRepeat)
+	if session == nil {
+		return
+	}
+
+	switch value {
+	case "none":
+		session.Repeat = ""
+		session.RepeatDays = nil
+		session.RepeatCount = 0
+		session.RepeatUntil = time.Time{}
+		advance(session, state.StepReview)
+	case model.FrequencyWeekly:
+		// Weekly stays on this step so the weekdays can be picked
+		session.Repeat = value
+		if len(session.RepeatDays) == 0 {
+			session.RepeatDays = []time.Weekday{session.Date.Weekday()}
+		}
+	case model.FrequencyDaily, model.FrequencyMonthly:
+		session.Repeat = value
+		session.RepeatDays = nil
+		session.Step = state.StepEnterRepeatEnd
+	default:
+		a.logger.Printf("Invalid repeat callback: %q", value)
+		return
+	}
+
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// repeatDayCallback toggles one weekday of a weekly series
+func (a *App) repeatDayCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepSelectRepeat)
+	if session == nil {
+		return
+	}
+
+	day, err := strconv.Atoi(value)
+	if err != nil || day < 0 || day > 6 {
+		a.logger.Printf("Invalid repeat day callback: %q", value)
+		return
+	}
+	weekday := time.Weekday(day)
+
+	if i := slices.Index(session.RepeatDays, weekday); i >= 0 {
+		session.RepeatDays = slices.Delete(session.RepeatDays, i, i+1)
+	} else {
+		session.RepeatDays = append(session.RepeatDays, weekday)
+		slices.SortFunc(session.RepeatDays, func(x, y time.Weekday) int {
+			// Monday first, Sunday last
+			return (int(x)+6)%7 - (int(y)+6)%7
+		})
+	}
+
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// repeatNextCallback moves from the weekday picker to the series length
+func (a *App) repeatNextCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery) {
+	session := a.activeSession(ctx, b, query, state.StepSelectRepeat)
+	if session == nil {
+		return
+	}
+
+	if len(session.RepeatDays) == 0 {
+		a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "Please pick at least one weekday.")
+		return
+	}
+
+	session.Step = state.StepEnterRepeatEnd
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// repeatCountCallback sets the series length from one of the buttons
+func (a *App) repeatCountCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	session := a.activeSession(ctx, b, query, state.StepEnterRepeatEnd)
+	if session == nil {
+		return
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid repeat count callback: %q", value)
+		return
+	}
+
+	if problem := setRepeatEnd(session, count, time.Time{}); problem != "" {
+		a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, problem)
+		return
+	}
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session, "")
+}
+
+// repeatEndStep reads a typed series length: a number of occurrences or an end date
+func (a *App) repeatEndStep(ctx context.Context, b *bot.Bot, message *models.Message, session *state.BookingSession) {
+	text := strings.TrimSpace(message.Text)
+
+	var problem string
+	if count, err := strconv.Atoi(text); err == nil {
+		problem = setRepeatEnd(session, count, time.Time{})
+	} else if until, err := time.ParseInLocation("2006-01-02", text, session.Date.Location()); err == nil {
+		problem = setRepeatEnd(session, 0, until)
+	} else {
+		problem = "Please type a number of times (e.g. 10) or an end date like 2026-12-31."
+	}
+
+	if problem != "" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   problem,
+		})
+		return
+	}
+	if !a.saveSession(ctx, b, message.Chat.ID, message.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, message.Chat.ID, 0, session, "")
+}
+
+// setRepeatEnd validates the series length and returns to review.
+// It returns a message for the user when the length is not allowed.
+func setRepeatEnd(session *state.BookingSession, count int, until time.Time) string {
+	if until.IsZero() && (count < 2 || count > service.MaxSeriesOccurrences) {
+		return fmt.Sprintf("A series can repeat between 2 and %d times.", service.MaxSeriesOccurrences)
+	}
+	if !until.IsZero() && (!until.After(session.Date) || until.After(session.Date.Add(service.MaxSeriesSpan))) {
+		return fmt.Sprintf("The end date must be after %s and within a year of it.", session.Date.Format("02 Jan 2006"))
+	}
+
+	session.RepeatCount = count
+	session.RepeatUntil = until
+	advance(session, state.StepReview)
+	return ""
+}
+
+// completeSeries books every date of the reviewed series that is free and
+// reports the dates that could not be booked.
+func (a *App) completeSeries(ctx context.Context, b *bot.Bot, chatID int64, from *models.User, session *state.BookingSession) {
+	fullName := strings.TrimSpace(from.FirstName + " " + from.LastName)
+	user, err := a.store.CreateOrGetUser(ctx, from.ID, from.Username, fullName)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	booking, err := sessionBooking(session)
+	if err != nil {
+		a.logger.Printf("Invalid booking session: %v", err)
+		return
+	}
+	booking.UserID = user.UserID
+
+	series := sessionSeries(session, booking)
+	occurrences, skipped := a.service.PlanSeries(series, session.RoomName)
+
+	booked, conflicts, err := a.store.CreateSeries(ctx, &series, occurrences, sessionParticipants(session))
+
+	// The session and its hold are finished either way
+	a.clearSession(ctx, from.ID)
+
+	for _, conflict := range conflicts {
+		skipped = append(skipped, service.SkippedOccurrence{Date: conflict.Date, Reason: "already booked"})
+	}
+	slices.SortFunc(skipped, func(x, y service.SkippedOccurrence) int {
+		return x.Date.Compare(y.Date)
+	})
+
+	if errors.Is(err, db.ErrSlotTaken) {
+		message := "😕 None of the dates in this series could be booked:\n"
+		for _, occurrence := range skipped {
+			message += fmt.Sprintf("  ❌ %s - %s\n", occurrence.Date.Format("Mon 02 Jan 2006"), occurrence.Reason)
+		}
+		message += "Type /book to try again."
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   message,
+		})
+		return
+	}
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, unable to create your booking. Type /book to try again.",
+		})
+		a.logger.Printf("Error creating booking series: %v", err)
+		return
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      a.service.FormatSeriesResult(series, session.RoomName, booked, skipped),
+		ParseMode: models.ParseModeMarkdown,
+	})
+
+	// One message for the whole series rather than one per occurrence
+	first := booked[0]
+	first.RoomName = session.RoomName
+	first.FullName = user.FullName
+	first.Participants = session.Participants
+	message := a.service.FormatParticipantNotice(service.NoticeAdded, first)
+	message += fmt.Sprintf("   🔁 %s, %d meetings\n", bot.EscapeMarkdown(a.service.FormatRecurrence(series)), len(booked))
+	a.notifyParticipants(ctx, b, first.BookingID, from.ID, message)
+}
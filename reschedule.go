@@ -0,0 +1,213 @@
+// reschedule.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/service"
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// rescheduleKeyboard builds one reschedule button per booking on the page
+func rescheduleKeyboard(bookings []model.Booking, page int) *models.InlineKeyboardMarkup {
+	return bookingListKeyboard(bookings, page, "🔁", "reschedule")
+}
+
+// rescheduleHandler lists the user's bookings with a button to move each one
+func (a *App) rescheduleHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	user, err := a.store.GetUserByTelegramID(ctx, update.Message.From.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	bookings, err := a.store.GetUserBookings(ctx, user.UserID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "Error retrieving your bookings.",
+		})
+		a.logger.Printf("Error getting bookings: %v", err)
+		return
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        a.service.FormatUserBookings(bookings, 0),
+		ParseMode:   models.ParseModeMarkdown,
+		ReplyMarkup: rescheduleKeyboard(bookings, 0),
+	})
+}
+
+// reschedulePageCallback shows another page of the booking list
+func (a *App) reschedulePageCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	page, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid reschedule page callback: %q", value)
+		return
+	}
+
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	bookings, err := a.store.GetUserBookings(ctx, user.UserID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving your bookings.",
+		})
+		a.logger.Printf("Error getting bookings: %v", err)
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      callbackChatID(query),
+		MessageID:   callbackMessageID(query),
+		Text:        a.service.FormatUserBookings(bookings, page),
+		ParseMode:   models.ParseModeMarkdown,
+		ReplyMarkup: rescheduleKeyboard(bookings, page),
+	})
+}
+
+// rescheduleCallback starts a session for moving the chosen booking. It walks
+// the same date, room and time steps as /book and then returns to review.
+func (a *App) rescheduleCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	bookingID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid reschedule callback: %q", value)
+		return
+	}
+
+	user, err := a.store.GetUserByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	booking, err := a.store.GetBookingByID(ctx, bookingID)
+	if err != nil || booking.UserID != user.UserID || booking.Status != "SUCCESS" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: callbackChatID(query),
+			Text:   "Error retrieving the booking.",
+		})
+		a.logger.Printf("Error getting booking %d for user %d: %v", bookingID, user.UserID, err)
+		return
+	}
+
+	// Replaces any booking conversation the user had going
+	a.clearSession(ctx, query.From.ID)
+
+	session := &state.BookingSession{
+		UserID:       query.From.ID,
+		Step:         state.StepSelectDate,
+		RoomID:       booking.RoomID,
+		RoomName:     booking.RoomName,
+		Date:         booking.Date,
+		StartTime:    booking.StartTime.Format("15:04"),
+		EndTime:      booking.EndTime.Format("15:04"),
+		Topic:        booking.Topic,
+		Participants: booking.Participants,
+		Editing:      true,
+		BookingID:    booking.BookingID,
+	}
+	if !a.saveSession(ctx, b, callbackChatID(query), query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, callbackChatID(query), callbackMessageID(query), session,
+		fmt.Sprintf("🔁 Rescheduling \"%s\" (%s %s - %s)", booking.Topic, booking.Date.Format("02 Jan"), session.StartTime, session.EndTime))
+}
+
+// completeReschedule moves the booking to the session's room and time and ends
+// the conversation. If the new time is taken the booking stays where it was.
+func (a *App) completeReschedule(ctx context.Context, b *bot.Bot, chatID int64, from *models.User, session *state.BookingSession) {
+	user, err := a.store.GetUserByTelegramID(ctx, from.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	booking, err := sessionBooking(session)
+	if err != nil {
+		a.logger.Printf("Invalid booking session: %v", err)
+		return
+	}
+	booking.BookingID = session.BookingID
+	booking.UserID = user.UserID
+	booking.FullName = user.FullName
+
+	// Needed afterwards to offer the range it leaves to the waitlist
+	previous, err := a.store.GetBookingByID(ctx, booking.BookingID)
+	if err != nil {
+		a.logger.Printf("Error getting booking %d: %v", booking.BookingID, err)
+	}
+
+	err = a.store.UpdateBookingTime(ctx, booking)
+
+	// The session and its hold are finished either way
+	a.clearSession(ctx, from.ID)
+
+	if errors.Is(err, db.ErrSlotTaken) {
+		a.slotTaken(ctx, b, chatID, from.ID, session)
+		return
+	}
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, unable to reschedule your booking. It has not been changed.",
+		})
+		a.logger.Printf("Error rescheduling booking %d: %v", booking.BookingID, err)
+		return
+	}
+
+	message := "✅ *Booking rescheduled\\!*\n\n"
+	message += fmt.Sprintf("🏢 %s\n", bot.EscapeMarkdown(session.RoomName))
+	message += fmt.Sprintf("📅 %s\n", booking.Date.Format("02 Jan 2006"))
+	message += fmt.Sprintf("⏰ %s \\- %s\n", session.StartTime, session.EndTime)
+	message += fmt.Sprintf("📝 %s\n", bot.EscapeMarkdown(session.Topic))
+	message += fmt.Sprintf("🔖 ID: `%d`", booking.BookingID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      message,
+		ParseMode: models.ParseModeMarkdown,
+	})
+
+	a.notifyParticipants(ctx, b, booking.BookingID, from.ID, a.service.FormatParticipantNotice(service.NoticeMoved, *booking))
+	if previous != nil && !sharesRange(*previous, *booking) {
+		a.offerWaitlist(ctx, b, bookingSlot(*previous))
+	}
+}
+
+// sharesRange reports whether two bookings overlap in the same room on the same day.
+// A booking moved within its old range leaves nothing for the waitlist.
+func sharesRange(a, b model.Booking) bool {
+	return a.RoomID == b.RoomID &&
+		a.Date.Format("2006-01-02") == b.Date.Format("2006-01-02") &&
+		a.StartTime.Format("15:04") < b.EndTime.Format("15:04") &&
+		b.StartTime.Format("15:04") < a.EndTime.Format("15:04")
+}
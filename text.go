@@ -0,0 +1,232 @@
+// text.go
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Column limits from the bookings and participants tables
+const (
+	maxTopicLength           = 200 // bookings.topic VARCHAR(200)
+	maxParticipantNameLength = 100 // participants.name VARCHAR(100)
+)
+
+// textStepHandler feeds free-text input to the current step of the user's booking session.
+// It returns false when the user has no session, so the caller can fall back.
+func (a *App) textStepHandler(ctx context.Context, b *bot.Bot, message *models.Message) bool {
+	session, err := a.loadSession(ctx, message.From.ID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   "Sorry, something went wrong. Please try again later.",
+		})
+		a.logger.Printf("Error getting session: %v", err)
+		return true
+	}
+	if session == nil {
+		return false
+	}
+
+	switch session.Step {
+	case state.StepEnterTopic:
+		a.topicStep(ctx, b, message, session)
+	case state.StepEnterParticipants:
+		a.participantsStep(ctx, b, message, session)
+	case state.StepEnterRepeatEnd:
+		a.repeatEndStep(ctx, b, message, session)
+	default:
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   "Please use the buttons above to continue your booking, or type /book to start again.",
+		})
+	}
+
+	return true
+}
+
+func (a *App) topicStep(ctx context.Context, b *bot.Bot, message *models.Message, session *state.BookingSession) {
+	topic := strings.TrimSpace(message.Text)
+
+	if topic == "" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   "The topic cannot be empty. Please type the meeting topic:",
+		})
+		return
+	}
+
+	if utf8.RuneCountInString(topic) > maxTopicLength {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   fmt.Sprintf("The topic is too long (max %d characters). Please type a shorter topic:", maxTopicLength),
+		})
+		return
+	}
+
+	session.Topic = topic
+	advance(session, state.StepEnterParticipants)
+	if !a.saveSession(ctx, b, message.Chat.ID, message.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, message.Chat.ID, 0, session, "")
+}
+
+// participantsStep replaces the typed participant names; shared contacts are kept.
+// @usernames of people who have used the bot are linked so they can be told about the booking.
+func (a *App) participantsStep(ctx context.Context, b *bot.Bot, message *models.Message, session *state.BookingSession) {
+	if message.Contact != nil {
+		a.participantContact(ctx, b, message, session)
+		return
+	}
+
+	names, err := parseParticipants(message.Text)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   fmt.Sprintf("Sorry, %v. Please type the participant names again:", err),
+		})
+		return
+	}
+
+	var participants []string
+	users := make(map[string]int)
+	for _, name := range session.Participants {
+		if userID, ok := session.ParticipantUsers[name]; ok && !strings.HasPrefix(name, "@") {
+			participants = append(participants, name)
+			users[name] = userID
+		}
+	}
+
+	var unknown []string
+	for _, name := range names {
+		if slices.ContainsFunc(participants, func(p string) bool { return strings.EqualFold(p, name) }) {
+			continue
+		}
+		participants = append(participants, name)
+
+		if !strings.HasPrefix(name, "@") {
+			continue
+		}
+		user, err := a.store.GetUserByUsername(ctx, name)
+		if errors.Is(err, sql.ErrNoRows) {
+			unknown = append(unknown, name)
+			continue
+		}
+		if err != nil {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: message.Chat.ID,
+				Text:   "Sorry, something went wrong. Please try again later.",
+			})
+			a.logger.Printf("Error getting user %s: %v", name, err)
+			return
+		}
+		users[name] = user.UserID
+	}
+
+	session.Participants = participants
+	session.ParticipantUsers = users
+	advance(session, state.StepReview)
+	if !a.saveSession(ctx, b, message.Chat.ID, message.From.ID, session) {
+		return
+	}
+
+	if len(unknown) > 0 {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   fmt.Sprintf("⚠ %s hasn't used this bot yet, so they won't get messages about the meeting.", strings.Join(unknown, ", ")),
+		})
+	}
+
+	a.showStep(ctx, b, message.Chat.ID, 0, session, "")
+}
+
+// participantContact adds a shared contact to the participants and stays on the
+// step, so several contacts can be shared before pressing Done
+func (a *App) participantContact(ctx context.Context, b *bot.Bot, message *models.Message, session *state.BookingSession) {
+	contact := message.Contact
+	name := strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+	if name == "" {
+		name = contact.PhoneNumber
+	}
+	if utf8.RuneCountInString(name) > maxParticipantNameLength {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: message.Chat.ID,
+			Text:   fmt.Sprintf("Sorry, the name %q is too long (max %d characters). Please type it instead:", name, maxParticipantNameLength),
+		})
+		return
+	}
+
+	notice := fmt.Sprintf("👤 Added %s.", name)
+	linked := false
+	if contact.UserID != 0 {
+		// Only people who have used the bot are linked; sharing a contact doesn't register them
+		user, err := a.store.GetUserByTelegramID(ctx, contact.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: message.Chat.ID,
+				Text:   "Sorry, something went wrong. Please try again later.",
+			})
+			a.logger.Printf("Error getting user: %v", err)
+			return
+		}
+		if err == nil {
+			if session.ParticipantUsers == nil {
+				session.ParticipantUsers = make(map[string]int)
+			}
+			session.ParticipantUsers[name] = user.UserID
+			linked = true
+		}
+	}
+	if !linked {
+		notice += " They haven't used this bot, so they won't get messages about the meeting."
+	}
+
+	if !slices.Contains(session.Participants, name) {
+		session.Participants = append(session.Participants, name)
+	}
+	if !a.saveSession(ctx, b, message.Chat.ID, message.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, message.Chat.ID, 0, session, notice)
+}
+
+// parseParticipants splits a comma or newline separated list of names
+func parseParticipants(text string) ([]string, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+
+	var participants []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		name := strings.TrimSpace(field)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxParticipantNameLength {
+			return nil, fmt.Errorf("name %q is too long (max %d characters)", name, maxParticipantNameLength)
+		}
+		seen[strings.ToLower(name)] = true
+		participants = append(participants, name)
+	}
+
+	if len(participants) == 0 {
+		return nil, fmt.Errorf("no participant names found")
+	}
+
+	return participants, nil
+}
@@ -0,0 +1,66 @@
+// text_test.go
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseParticipants(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "comma separated",
+			text: "Alice, Bob,Carol",
+			want: []string{"Alice", "Bob", "Carol"},
+		},
+		{
+			name: "one per line",
+			text: "Alice\nBob\n\nCarol\n",
+			want: []string{"Alice", "Bob", "Carol"},
+		},
+		{
+			name: "duplicates ignoring case",
+			text: "Alice, alice, ALICE, Bob",
+			want: []string{"Alice", "Bob"},
+		},
+		{
+			name: "usernames kept as typed",
+			text: "@alice_smith, Bob",
+			want: []string{"@alice_smith", "Bob"},
+		},
+		{
+			name:    "only separators",
+			text:    " , ,\n",
+			wantErr: true,
+		},
+		{
+			name:    "name too long",
+			text:    "Alice, " + strings.Repeat("x", maxParticipantNameLength+1),
+			wantErr: true,
+		},
+		{
+			name: "longest name allowed",
+			text: strings.Repeat("é", maxParticipantNameLength),
+			want: []string{strings.Repeat("é", maxParticipantNameLength)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseParticipants(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseParticipants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseParticipants() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
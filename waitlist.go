@@ -0,0 +1,294 @@
+// waitlist.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegrarmchatbot/db"
+	"telegrarmchatbot/internal/model"
+	"telegrarmchatbot/internal/state"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// bookingSlot is the room, date and time range a booking occupies, as a waitlist key
+func bookingSlot(booking model.Booking) model.WaitlistEntry {
+	return model.WaitlistEntry{
+		RoomID:    booking.RoomID,
+		Date:      booking.Date,
+		StartTime: booking.StartTime,
+		EndTime:   booking.EndTime,
+		RoomName:  booking.RoomName,
+	}
+}
+
+// waitlistKeyboard offers to join the waitlist of each booking in the schedule
+func waitlistKeyboard(schedule *model.RoomSchedule) [][]models.InlineKeyboardButton {
+	var rows [][]models.InlineKeyboardButton
+	var last *model.Booking
+	for _, slot := range schedule.TimeSlots {
+		// A booking spanning several slots gets one button
+		if slot.Booking == nil || slot.Booking == last {
+			continue
+		}
+		last = slot.Booking
+
+		label := fmt.Sprintf("📋 Join waitlist %s-%s", slot.Booking.StartTime.Format("15:04"), slot.Booking.EndTime.Format("15:04"))
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: label, CallbackData: callbackData("waitlist", strconv.Itoa(slot.Booking.BookingID))},
+		})
+	}
+	return rows
+}
+
+// waitlistCallback queues the user for the time range of a booked slot
+func (a *App) waitlistCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	bookingID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid waitlist callback: %q", value)
+		return
+	}
+	chatID := callbackChatID(query)
+
+	fullName := strings.TrimSpace(query.From.FirstName + " " + query.From.LastName)
+	user, err := a.store.CreateOrGetUser(ctx, query.From.ID, query.From.Username, fullName)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Error retrieving your information.",
+		})
+		a.logger.Printf("Error getting user: %v", err)
+		return
+	}
+
+	booking, err := a.store.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Error retrieving the booking.",
+		})
+		a.logger.Printf("Error getting booking %d: %v", bookingID, err)
+		return
+	}
+	if booking.Status != "SUCCESS" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "That slot is free again. Type /book to book it.",
+		})
+		return
+	}
+	if booking.UserID == user.UserID {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "That slot is your own booking.",
+		})
+		return
+	}
+
+	entry := bookingSlot(*booking)
+	entry.UserID = user.UserID
+	position, err := a.store.JoinWaitlist(ctx, &entry)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, unable to join the waitlist. Please try again later.",
+		})
+		a.logger.Printf("Error joining waitlist for booking %d: %v", bookingID, err)
+		return
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text: fmt.Sprintf("📋 You are #%d on the waitlist for %s %s %s-%s.\nI'll message you if it frees up.",
+			position, booking.RoomName, booking.Date.Format("02 Jan"), booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04")),
+	})
+}
+
+// offerHolder is the holder of the hold placed for a waitlist offer. It is kept
+// apart from the user's own hold, which a booking they have open may be using;
+// Telegram user IDs are positive, so a negated waitlist ID never clashes.
+func offerHolder(waitlistID int) int64 {
+	return -int64(waitlistID)
+}
+
+// offerWaitlist offers a freed slot to the first user waiting for it and holds
+// it for the offer while they decide
+func (a *App) offerWaitlist(ctx context.Context, b *bot.Bot, slot model.WaitlistEntry) {
+	ttl := a.config.Booking.WaitlistOfferDuration()
+
+	entry, err := a.store.OfferNextWaitlist(ctx, slot, a.now().Add(ttl))
+	if err != nil {
+		a.logger.Printf("Error offering waitlist slot: %v", err)
+		return
+	}
+	if entry == nil {
+		return
+	}
+
+	if _, err := a.holds.Hold(ctx, entry.RoomID, localDate(entry.Date), entry.StartTime.Format("15:04"), entry.EndTime.Format("15:04"), offerHolder(entry.WaitlistID), ttl); err != nil {
+		a.logger.Printf("Error holding offered slot: %v", err)
+	}
+
+	value := strconv.Itoa(entry.WaitlistID)
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: entry.TelegramID,
+		Text: fmt.Sprintf("🎉 %s %s %s-%s is free!\nIt's yours if you claim it within %d minutes.",
+			entry.RoomName, entry.Date.Format("02 Jan 2006"), entry.StartTime.Format("15:04"), entry.EndTime.Format("15:04"), a.config.Booking.WaitlistOffer),
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: "✅ Claim", CallbackData: callbackData("waitlist_claim", value)},
+					{Text: "✖ Pass", CallbackData: callbackData("waitlist_pass", value)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		a.logger.Printf("Error sending waitlist offer %d: %v", entry.WaitlistID, err)
+	}
+}
+
+// localDate moves a DATE column value, which the driver returns at UTC midnight,
+// to local midnight like the dates picked from the calendar
+func localDate(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// releaseOfferHold drops the hold placed for an offer
+func (a *App) releaseOfferHold(ctx context.Context, entry *model.WaitlistEntry) {
+	if err := a.holds.Release(ctx, offerHolder(entry.WaitlistID)); err != nil {
+		a.logger.Printf("Error releasing offered slot hold: %v", err)
+	}
+}
+
+// waitlistClaimCallback accepts an offer and continues as a normal booking from the topic
+func (a *App) waitlistClaimCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	waitlistID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid waitlist claim callback: %q", value)
+		return
+	}
+	chatID := callbackChatID(query)
+
+	entry, err := a.store.ClaimWaitlistOffer(ctx, waitlistID, query.From.ID, a.now())
+	if errors.Is(err, db.ErrNoOffer) {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    chatID,
+			MessageID: callbackMessageID(query),
+			Text:      "⌛ This offer has expired.",
+		})
+		return
+	}
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "Sorry, something went wrong. Please try again later.",
+		})
+		a.logger.Printf("Error claiming waitlist offer %d: %v", waitlistID, err)
+		return
+	}
+
+	session := &state.BookingSession{
+		UserID:    query.From.ID,
+		Step:      state.StepEnterTopic,
+		RoomID:    entry.RoomID,
+		RoomName:  entry.RoomName,
+		Date:      localDate(entry.Date),
+		StartTime: entry.StartTime.Format("15:04"),
+		EndTime:   entry.EndTime.Format("15:04"),
+	}
+
+	// The offer's hold passes to the user while the details are typed, as after
+	// picking an end time. Their own hold on anything else is replaced.
+	a.releaseOfferHold(ctx, entry)
+	held, err := a.holds.Hold(ctx, session.RoomID, session.Date, session.StartTime, session.EndTime, query.From.ID, a.config.Booking.HoldTTL())
+	if err != nil {
+		a.logger.Printf("Error holding claimed slot: %v", err)
+	}
+	if !held {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    chatID,
+			MessageID: callbackMessageID(query),
+			Text:      "😕 Sorry, someone else has taken this slot in the meantime.",
+		})
+		return
+	}
+
+	// Replaces any booking conversation the user had going
+	if !a.saveSession(ctx, b, chatID, query.From.ID, session) {
+		return
+	}
+
+	a.showStep(ctx, b, chatID, callbackMessageID(query), session, "🎉 Claimed! Finish the booking to keep it.")
+}
+
+// waitlistPassCallback declines an offer and passes the slot to the next user
+func (a *App) waitlistPassCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, value string) {
+	waitlistID, err := strconv.Atoi(value)
+	if err != nil {
+		a.logger.Printf("Invalid waitlist pass callback: %q", value)
+		return
+	}
+
+	entry, err := a.store.PassWaitlistOffer(ctx, waitlistID, query.From.ID)
+	if errors.Is(err, db.ErrNoOffer) {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    callbackChatID(query),
+			MessageID: callbackMessageID(query),
+			Text:      "⌛ This offer has expired.",
+		})
+		return
+	}
+	if err != nil {
+		a.logger.Printf("Error passing waitlist offer %d: %v", waitlistID, err)
+		return
+	}
+
+	a.releaseOfferHold(ctx, entry)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    callbackChatID(query),
+		MessageID: callbackMessageID(query),
+		Text:      "👍 No problem, the slot goes to the next person waiting.",
+	})
+
+	a.offerWaitlist(ctx, b, *entry)
+}
+
+// watchWaitlist moves unanswered offers on to the next waiting user,
+// checking every minute until ctx is done
+func (a *App) watchWaitlist(ctx context.Context, b *bot.Bot) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := a.store.ExpireWaitlistOffers(ctx, a.now())
+		if err != nil {
+			a.logger.Printf("Error expiring waitlist offers: %v", err)
+			continue
+		}
+
+		for _, entry := range expired {
+			a.releaseOfferHold(ctx, &entry)
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: entry.TelegramID,
+				Text: fmt.Sprintf("⌛ Your offer for %s %s %s-%s has expired.",
+					entry.RoomName, entry.Date.Format("02 Jan"), entry.StartTime.Format("15:04"), entry.EndTime.Format("15:04")),
+			})
+			a.offerWaitlist(ctx, b, entry)
+		}
+	}
+}